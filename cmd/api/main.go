@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"log"
 	"store_app/internal/config"
 	"store_app/internal/database"
+	"store_app/internal/jobs"
 	"store_app/internal/router"
 )
 
@@ -18,6 +20,9 @@ func main() {
 
 	resetUsers(db)
 
+	// Start background jobs
+	jobs.Start(context.Background(), db, cfg)
+
 	// Setup router
 	router := router.SetupRouter(db)
 
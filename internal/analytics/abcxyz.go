@@ -0,0 +1,142 @@
+// Package analytics считает аналитические показатели по истории продаж:
+// ABC/XYZ-классификацию товаров и прогноз спроса
+package analytics
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"store_app/internal/models"
+)
+
+// ABCThresholds - границы классов A и B по накопленной доле выручки, в процентах.
+// Товары, на которые приходятся первые A% выручки, - класс A, следующие до B% - класс B, остальные - C.
+type ABCThresholds struct {
+	A float64
+	B float64
+}
+
+// XYZThresholds - границы классов X и Y по коэффициенту вариации недельного спроса, в процентах.
+// Вариация до X% - класс X (стабильный спрос), до Y% - класс Y, выше или без продаж - Z.
+type XYZThresholds struct {
+	X float64
+	Y float64
+}
+
+// Границы классов по умолчанию
+var (
+	DefaultABC = ABCThresholds{A: 80, B: 95}
+	DefaultXYZ = XYZThresholds{X: 10, Y: 25}
+)
+
+// Validate проверяет границы классов ABC
+func (t ABCThresholds) Validate() error {
+	if t.A <= 0 || t.A >= t.B || t.B > 100 {
+		return errors.New("границы ABC должны быть 0 < A < B <= 100")
+	}
+	return nil
+}
+
+// Validate проверяет границы классов XYZ
+func (t XYZThresholds) Validate() error {
+	if t.X <= 0 || t.X >= t.Y {
+		return errors.New("границы XYZ должны быть 0 < X < Y")
+	}
+	return nil
+}
+
+// ProductSales - продажи товара за период анализа: выручка и проданное количество по неделям
+type ProductSales struct {
+	WarehouseID int
+	Name        string
+	Revenue     float64
+	Weekly      []float64
+}
+
+// Classify относит товары к классам ABC по накопленной доле выручки и XYZ по коэффициенту
+// вариации недельного спроса. Результат отсортирован по убыванию выручки. Товар, на котором
+// накопленная доля переходит границу класса, остается в старшем классе.
+func Classify(products []ProductSales, abc ABCThresholds, xyz XYZThresholds) []models.ABCXYZItem {
+	sorted := make([]ProductSales, len(products))
+	copy(sorted, products)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Revenue != sorted[j].Revenue {
+			return sorted[i].Revenue > sorted[j].Revenue
+		}
+		return sorted[i].WarehouseID < sorted[j].WarehouseID
+	})
+
+	var total float64
+	for _, p := range sorted {
+		if p.Revenue > 0 {
+			total += p.Revenue
+		}
+	}
+
+	items := make([]models.ABCXYZItem, 0, len(sorted))
+	var cumulative float64
+	for _, p := range sorted {
+		item := models.ABCXYZItem{
+			WarehouseID: p.WarehouseID,
+			Name:        p.Name,
+			Revenue:     round2(p.Revenue),
+			ABC:         "C",
+		}
+
+		if total > 0 && p.Revenue > 0 {
+			share := p.Revenue / total * 100
+			switch {
+			case cumulative < abc.A:
+				item.ABC = "A"
+			case cumulative < abc.B:
+				item.ABC = "B"
+			}
+			cumulative += share
+			item.RevenueShare = round2(share)
+		}
+		item.CumulativeShare = round2(cumulative)
+
+		item.WeeklyMean, item.WeeklyStdDev = meanStdDev(p.Weekly)
+		item.XYZ = "Z"
+		if item.WeeklyMean > 0 {
+			cv := item.WeeklyStdDev / item.WeeklyMean * 100
+			switch {
+			case cv <= xyz.X:
+				item.XYZ = "X"
+			case cv <= xyz.Y:
+				item.XYZ = "Y"
+			}
+			cv = round2(cv)
+			item.Variation = &cv
+		}
+		item.WeeklyMean = round2(item.WeeklyMean)
+		item.WeeklyStdDev = round2(item.WeeklyStdDev)
+		item.Class = item.ABC + item.XYZ
+
+		items = append(items, item)
+	}
+	return items
+}
+
+// meanStdDev возвращает среднее и стандартное отклонение (по генеральной совокупности)
+func meanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,74 @@
+package analytics
+
+import "testing"
+
+func TestClassifyABC(t *testing.T) {
+	tests := []struct {
+		name     string
+		revenues []float64
+		want     []string
+	}{
+		{"ровно на границах", []float64{50, 30, 15, 5}, []string{"A", "A", "B", "C"}},
+		// Товар, на котором накопленная доля переходит границу, остается в старшем классе
+		{"переход границы", []float64{70, 20, 10}, []string{"A", "A", "B"}},
+		{"один товар", []float64{100}, []string{"A"}},
+		{"без выручки", []float64{40, 0}, []string{"A", "C"}},
+		{"возврат больше продаж", []float64{60, -10}, []string{"A", "C"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products := make([]ProductSales, len(tt.revenues))
+			for i, r := range tt.revenues {
+				products[i] = ProductSales{WarehouseID: i + 1, Revenue: r}
+			}
+
+			items := Classify(products, DefaultABC, DefaultXYZ)
+			for i, item := range items {
+				if item.ABC != tt.want[i] {
+					t.Errorf("товар %d (выручка %v): класс %s, ожидался %s", item.WarehouseID, item.Revenue, item.ABC, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestClassifyXYZ(t *testing.T) {
+	tests := []struct {
+		name          string
+		weekly        []float64
+		want          string
+		wantVariation *float64
+	}{
+		{"стабильный спрос", []float64{5, 5, 5, 5}, "X", ptr(0)},
+		{"на границе X", []float64{9, 11}, "X", ptr(10)},
+		{"на границе Y", []float64{3, 5}, "Y", ptr(25)},
+		{"нестабильный спрос", []float64{0, 10}, "Z", ptr(100)},
+		{"без продаж", []float64{0, 0, 0}, "Z", nil},
+		{"нет недель", nil, "Z", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := Classify([]ProductSales{{WarehouseID: 1, Revenue: 100, Weekly: tt.weekly}}, DefaultABC, DefaultXYZ)
+			item := items[0]
+
+			if item.XYZ != tt.want {
+				t.Errorf("класс %s, ожидался %s", item.XYZ, tt.want)
+			}
+			switch {
+			case tt.wantVariation == nil && item.Variation != nil:
+				t.Errorf("вариация %v, ожидалось nil", *item.Variation)
+			case tt.wantVariation != nil && (item.Variation == nil || *item.Variation != *tt.wantVariation):
+				t.Errorf("вариация %v, ожидалось %v", item.Variation, *tt.wantVariation)
+			}
+			if item.Class != "A"+tt.want {
+				t.Errorf("итоговый класс %s, ожидался A%s", item.Class, tt.want)
+			}
+		})
+	}
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,166 @@
+package analytics
+
+import (
+	"errors"
+	"math"
+)
+
+// Методы прогноза спроса
+const (
+	MethodMovingAverage = "moving_average"
+	MethodExponential   = "exponential"
+)
+
+// seasonLength - длина сезона в днях: спрос зависит от дня недели
+const seasonLength = 7
+
+// SafetyFactor - коэффициент страхового запаса, соответствует уровню сервиса около 95%
+const SafetyFactor = 1.65
+
+// ForecastParams - параметры прогноза. Window - окно скользящего среднего в днях,
+// Alpha и Gamma - коэффициенты сглаживания уровня и сезонности для экспоненциального метода.
+type ForecastParams struct {
+	Method string
+	Window int
+	Alpha  float64
+	Gamma  float64
+}
+
+// DefaultForecast - параметры прогноза по умолчанию
+var DefaultForecast = ForecastParams{Method: MethodExponential, Window: 28, Alpha: 0.3, Gamma: 0.1}
+
+// Validate проверяет параметры прогноза
+func (p ForecastParams) Validate() error {
+	switch p.Method {
+	case MethodMovingAverage:
+		if p.Window < 1 {
+			return errors.New("окно скользящего среднего должно быть не меньше дня")
+		}
+	case MethodExponential:
+		if p.Alpha <= 0 || p.Alpha > 1 || p.Gamma < 0 || p.Gamma > 1 {
+			return errors.New("коэффициенты сглаживания должны быть 0 < alpha <= 1, 0 <= gamma <= 1")
+		}
+	default:
+		return errors.New("неизвестный метод прогноза")
+	}
+	return nil
+}
+
+// Forecast прогнозирует спрос на days дней после истории продаж по дням (старые дни первыми).
+// Сезонность по дням недели учитывается, если в истории не меньше двух полных недель.
+// Прогноз не бывает отрицательным.
+func Forecast(history []float64, days int, p ForecastParams) []float64 {
+	forecast := make([]float64, days)
+	if len(history) == 0 {
+		return forecast
+	}
+
+	var level float64
+	var season [seasonLength]float64
+	if p.Method == MethodMovingAverage {
+		level, season = movingAverage(history, p.Window)
+	} else {
+		level, season = exponentialSmoothing(history, p.Alpha, p.Gamma)
+	}
+
+	for i := range forecast {
+		forecast[i] = math.Max(0, level+season[(len(history)+i)%seasonLength])
+	}
+	return forecast
+}
+
+// movingAverage - уровень как среднее последних window дней, сезонность - среднее
+// отклонение каждого дня недели от среднего за всю историю
+func movingAverage(history []float64, window int) (float64, [seasonLength]float64) {
+	var season [seasonLength]float64
+	if window > len(history) {
+		window = len(history)
+	}
+	level, _ := meanStdDev(history[len(history)-window:])
+
+	if len(history) < 2*seasonLength {
+		return level, season
+	}
+
+	mean, _ := meanStdDev(history)
+	var counts [seasonLength]int
+	for i, v := range history {
+		season[i%seasonLength] += v
+		counts[i%seasonLength]++
+	}
+	for k := range season {
+		season[k] = season[k]/float64(counts[k]) - mean
+	}
+	return level, season
+}
+
+// exponentialSmoothing - экспоненциальное сглаживание с аддитивной недельной сезонностью
+// (Хольт-Винтерс без тренда). Начальный уровень - среднее первой недели, начальная
+// сезонность - отклонения дней первой недели от него. При короткой истории - простое сглаживание.
+func exponentialSmoothing(history []float64, alpha, gamma float64) (float64, [seasonLength]float64) {
+	var season [seasonLength]float64
+	if len(history) < 2*seasonLength {
+		level := history[0]
+		for _, v := range history[1:] {
+			level = alpha*v + (1-alpha)*level
+		}
+		return level, season
+	}
+
+	level, _ := meanStdDev(history[:seasonLength])
+	for k := range season {
+		season[k] = history[k] - level
+	}
+	for i := seasonLength; i < len(history); i++ {
+		k := i % seasonLength
+		level = alpha*(history[i]-season[k]) + (1-alpha)*level
+		season[k] = gamma*(history[i]-level) + (1-gamma)*season[k]
+	}
+	return level, season
+}
+
+// Reorder - рекомендация по закупке товара
+type Reorder struct {
+	// LeadTimeDemand - прогноз спроса за срок поставки
+	LeadTimeDemand float64
+	// SafetyStock - страховой запас на колебания спроса за срок поставки
+	SafetyStock float64
+	// ReorderPoint - остаток, при котором пора заказывать
+	ReorderPoint float64
+	// StockoutDay - через сколько дней по прогнозу закончится остаток (nil - хватит на весь прогноз)
+	StockoutDay *int
+	// Quantity - сколько заказать, чтобы после поставки хватило на horizon дней; 0 - заказывать рано
+	Quantity int
+}
+
+// SuggestReorder рассчитывает закупку по истории продаж, текущему остатку и сроку поставки.
+// Заказ нужен, когда остаток не выше прогноза спроса за срок поставки со страховым запасом.
+// Заказ покрывает спрос за срок поставки и horizon дней после нее.
+func SuggestReorder(history []float64, quantity, leadTime, horizon int, p ForecastParams) Reorder {
+	forecast := Forecast(history, leadTime+horizon, p)
+	_, stdDev := meanStdDev(history)
+
+	var r Reorder
+	var demand float64
+	for i, v := range forecast {
+		demand += v
+		if i < leadTime {
+			r.LeadTimeDemand += v
+		}
+		if r.StockoutDay == nil && demand > float64(quantity) {
+			day := i + 1
+			r.StockoutDay = &day
+		}
+	}
+
+	r.SafetyStock = SafetyFactor * stdDev * math.Sqrt(float64(leadTime))
+	r.ReorderPoint = r.LeadTimeDemand + r.SafetyStock
+	if float64(quantity) <= r.ReorderPoint {
+		r.Quantity = int(math.Max(0, math.Ceil(demand+r.SafetyStock-float64(quantity))))
+	}
+
+	r.LeadTimeDemand = round2(r.LeadTimeDemand)
+	r.SafetyStock = round2(r.SafetyStock)
+	r.ReorderPoint = round2(r.ReorderPoint)
+	return r
+}
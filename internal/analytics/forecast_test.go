@@ -0,0 +1,74 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+)
+
+// weeklyPeak возвращает историю из days дней, в которой продажи (10 шт.) только в день peak каждой недели
+func weeklyPeak(days, peak int) []float64 {
+	history := make([]float64, days)
+	for i := range history {
+		if i%seasonLength == peak {
+			history[i] = 10
+		}
+	}
+	return history
+}
+
+func TestForecastSeasonAlignment(t *testing.T) {
+	methods := []ForecastParams{
+		{Method: MethodMovingAverage, Window: 1000},
+		DefaultForecast,
+	}
+	// Пиковый день прогноза должен продолжать недельный ритм истории при любой ее длине
+	tests := []struct {
+		days, peak  int
+		wantPeakDay int
+	}{
+		{14, 0, 0},
+		{14, 3, 3},
+		{15, 0, 6},
+		{17, 2, 6},
+		{20, 6, 0},
+		{27, 5, 6},
+	}
+
+	for _, p := range methods {
+		for _, tt := range tests {
+			forecast := Forecast(weeklyPeak(tt.days, tt.peak), seasonLength, p)
+			for i, v := range forecast {
+				want := 0.0
+				if i == tt.wantPeakDay {
+					want = 10
+				}
+				if math.Abs(v-want) > 1e-9 {
+					t.Errorf("%s, история %d дней, пик в день %d: прогноз на день %d = %v, ожидалось %v",
+						p.Method, tt.days, tt.peak, i, v, want)
+				}
+			}
+		}
+	}
+}
+
+func TestSuggestReorderSeasonAlignment(t *testing.T) {
+	// Срок поставки - один день: спрос за него есть, только если следующий день пиковый
+	tests := []struct {
+		name           string
+		days           int
+		wantLeadDemand float64
+	}{
+		{"завтра пик", 14, 10},
+		{"завтра без продаж", 15, 0},
+		{"три полные недели", 21, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := SuggestReorder(weeklyPeak(tt.days, 0), 100, 1, 6, DefaultForecast)
+			if r.LeadTimeDemand != tt.wantLeadDemand {
+				t.Errorf("спрос за срок поставки %v, ожидалось %v", r.LeadTimeDemand, tt.wantLeadDemand)
+			}
+		})
+	}
+}
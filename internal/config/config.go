@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 )
 
 // DatabaseConfig содержит настройки базы данных
@@ -27,12 +28,71 @@ type WebServerConfig struct {
 	Enable    bool
 }
 
+// SalesConfig содержит правила оформления продаж
+type SalesConfig struct {
+	// MaxDiscountPercent - максимальная ручная скидка (в процентах), доступная без прав администратора
+	MaxDiscountPercent float64
+	// PricesIncludeTax - цены товаров указаны с учетом НДС
+	PricesIncludeTax bool
+}
+
+// AlertsConfig содержит настройки оповещений
+type AlertsConfig struct {
+	// WebhookURL - адрес, на который отправляются события (пусто - только лог и журнал событий)
+	WebhookURL     string
+	WebhookTimeout time.Duration
+}
+
+// ReservationsConfig содержит настройки резервирования товара
+type ReservationsConfig struct {
+	// TTL - срок действия резерва по умолчанию
+	TTL time.Duration
+	// SweepInterval - как часто снимать просроченные резервы
+	SweepInterval time.Duration
+}
+
+// PricesConfig содержит настройки цен
+type PricesConfig struct {
+	// SweepInterval - как часто применять запланированные изменения цен
+	SweepInterval time.Duration
+}
+
+// ChargesConfig содержит настройки расходов
+type ChargesConfig struct {
+	// RecurringInterval - как часто создавать расходы по шаблонам повторяющихся расходов
+	RecurringInterval time.Duration
+}
+
+// AttachmentsConfig содержит настройки вложений (сканы счетов, чеков)
+type AttachmentsConfig struct {
+	// Dir - каталог локального хранилища файлов
+	Dir string
+	// MaxSize - максимальный размер файла в байтах
+	MaxSize int64
+}
+
+// StoreConfig содержит настройки магазина
+type StoreConfig struct {
+	// TimeZone - часовой пояс магазина, в нем считаются часы и дни недели в отчетах
+	TimeZone string
+	// DataTimeZone - часовой пояс, в котором записано время в БД (timestamp without time zone
+	// хранит время API-сервера без пояса)
+	DataTimeZone string
+}
+
 // Config основная структура конфигурации
 type Config struct {
-	Database  DatabaseConfig
-	ApiServer ServerConfig
-	WebServer WebServerConfig
-	JWTSecret string
+	Database     DatabaseConfig
+	ApiServer    ServerConfig
+	WebServer    WebServerConfig
+	Sales        SalesConfig
+	Alerts       AlertsConfig
+	Reservations ReservationsConfig
+	Prices       PricesConfig
+	Charges      ChargesConfig
+	Attachments  AttachmentsConfig
+	Store        StoreConfig
+	JWTSecret    string
 }
 
 // Load загружает конфигурацию из переменных окружения
@@ -53,6 +113,32 @@ func Load() *Config {
 			StaticDir: getEnv("WEB_STATIC_DIR", "./web/static"),
 			Enable:    getEnvBool("WEB_SERVER_ENABLE", true),
 		},
+		Sales: SalesConfig{
+			MaxDiscountPercent: getEnvFloat("MAX_DISCOUNT_PERCENT", 10),
+			PricesIncludeTax:   getEnvBool("PRICES_INCLUDE_TAX", true),
+		},
+		Alerts: AlertsConfig{
+			WebhookURL:     getEnv("ALERT_WEBHOOK_URL", ""),
+			WebhookTimeout: getEnvDuration("ALERT_WEBHOOK_TIMEOUT", 5*time.Second),
+		},
+		Reservations: ReservationsConfig{
+			TTL:           getEnvDuration("RESERVATION_TTL", 24*time.Hour),
+			SweepInterval: getEnvDuration("RESERVATION_SWEEP_INTERVAL", time.Minute),
+		},
+		Prices: PricesConfig{
+			SweepInterval: getEnvDuration("PRICE_SWEEP_INTERVAL", time.Minute),
+		},
+		Charges: ChargesConfig{
+			RecurringInterval: getEnvDuration("RECURRING_CHARGES_INTERVAL", time.Hour),
+		},
+		Attachments: AttachmentsConfig{
+			Dir:     getEnv("ATTACHMENTS_DIR", "./data/attachments"),
+			MaxSize: getEnvInt64("ATTACHMENTS_MAX_SIZE", 10<<20),
+		},
+		Store: StoreConfig{
+			TimeZone:     getEnv("STORE_TIMEZONE", "UTC"),
+			DataTimeZone: getEnv("DATA_TIMEZONE", "UTC"),
+		},
 		JWTSecret: getEnv("JWT_SECRET", "Z6w3uwI5Bx9btGcB9dtkShcGVaQAHVe/Ljg1a7tIKhE="),
 	}
 }
@@ -74,6 +160,38 @@ func getEnvBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+func getEnvInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		result, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			return result
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		result, err := strconv.ParseFloat(value, 64)
+		if err == nil {
+			return result
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDuration читает положительную длительность: нулевой или отрицательный интервал
+// фоновой задачи или таймаут бессмысленны, вместо них берется значение по умолчанию
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		result, err := time.ParseDuration(value)
+		if err == nil && result > 0 {
+			return result
+		}
+	}
+	return defaultValue
+}
+
 // GetDBConnectionString возвращает строку подключения к БД
 func (c *DatabaseConfig) GetDBConnectionString() string {
 	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
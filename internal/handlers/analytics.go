@@ -0,0 +1,396 @@
+// handlers/analytics.go
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"store_app/internal/analytics"
+	"store_app/internal/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetABCXYZReport классифицирует товары за период: ABC - по накопленной доле выручки
+// (границы a_threshold и b_threshold, в процентах), XYZ - по коэффициенту вариации проданного
+// количества по неделям (границы x_threshold и y_threshold, в процентах). Недели отсчитываются
+// от start_date, продажи последней неполной недели входят в выручку, но не в XYZ.
+// В отчет входят активные товары и товары с продажами за период,
+// фильтры category_id (с подкатегориями) и location_id сужают выборку.
+func (h *ReportsHandler) GetABCXYZReport(c *gin.Context) {
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	categoryID, ok := queryInt(c, "category_id")
+	if !ok {
+		return
+	}
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
+
+	abc, xyz := analytics.DefaultABC, analytics.DefaultXYZ
+	for _, p := range []struct {
+		name  string
+		value *float64
+	}{
+		{"a_threshold", &abc.A},
+		{"b_threshold", &abc.B},
+		{"x_threshold", &xyz.X},
+		{"y_threshold", &xyz.Y},
+	} {
+		v, ok := queryFloat(c, p.name)
+		if !ok {
+			return
+		}
+		if v != nil {
+			*p.value = *v
+		}
+	}
+
+	for _, err := range []error{abc.Validate(), xyz.Validate()} {
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+	}
+
+	weeks := (int(endDate.Sub(startDate).Hours()/24) + 1) / 7
+	if weeks < 2 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Для XYZ-анализа нужен период не меньше двух недель",
+		})
+		return
+	}
+
+	// По строке на товар и неделю продаж; товар без продаж - одна строка с пустой неделей
+	rows, err := h.DB.Query(`
+		SELECT w.id, w.name, (s.sale_date::date - $1::date) / 7 as week,
+		       COALESCE(SUM(s.amount), 0), COALESCE(SUM(s.quantity), 0)
+		FROM warehouses w
+		LEFT JOIN sales s ON w.id = s.warehouse_id AND s.sale_date BETWEEN $1 AND $2
+			AND ($4::int IS NULL OR s.location_id = $4)
+		WHERE `+categoryFilter("w.category_id", 3)+` AND (w.is_active OR s.id IS NOT NULL)
+		GROUP BY w.id, w.name, week
+		ORDER BY w.id, week
+	`, startDate, endDate, categoryID, locationID)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения продаж для анализа",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var products []analytics.ProductSales
+	for rows.Next() {
+		var id int
+		var name string
+		var week *int
+		var revenue, quantity float64
+		if err := rows.Scan(&id, &name, &week, &revenue, &quantity); err != nil {
+			continue
+		}
+
+		if len(products) == 0 || products[len(products)-1].WarehouseID != id {
+			products = append(products, analytics.ProductSales{
+				WarehouseID: id,
+				Name:        name,
+				Weekly:      make([]float64, weeks),
+			})
+		}
+		p := &products[len(products)-1]
+		p.Revenue += revenue
+		if week != nil && *week < weeks {
+			p.Weekly[*week] += quantity
+		}
+	}
+
+	items := analytics.Classify(products, abc, xyz)
+
+	summary := make(map[string]int)
+	for _, item := range items {
+		summary[item.Class]++
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"period": reportPeriod(startDate, endDate),
+			"weeks":  weeks,
+			"thresholds": gin.H{
+				"a": abc.A,
+				"b": abc.B,
+				"x": xyz.X,
+				"y": xyz.Y,
+			},
+			"items":   items,
+			"summary": summary,
+		},
+	})
+}
+
+// GetForecastReport прогнозирует спрос на активные товары на days дней по продажам за
+// history_days последних полных дней и рекомендует закупку с учетом остатка и срока поставки
+// основного поставщика (без поставщика или срока - lead_time_days, по умолчанию 7 дней).
+// Метод прогноза method: exponential (по умолчанию, параметры alpha и gamma) или
+// moving_average (параметр window). Фильтры: warehouse_id, category_id с подкатегориями,
+// reorder_only - только товары, которые пора заказать. Товары, которые пора заказать, идут первыми.
+func (h *ReportsHandler) GetForecastReport(c *gin.Context) {
+	params := analytics.DefaultForecast
+	if method := c.Query("method"); method != "" {
+		params.Method = method
+	}
+
+	intParams := map[string]int{"days": 14, "history_days": 90, "lead_time_days": 7, "window": params.Window}
+	for name := range intParams {
+		v, ok := queryInt(c, name)
+		if !ok {
+			return
+		}
+		if v != nil {
+			intParams[name] = *v
+		}
+	}
+	params.Window = intParams["window"]
+
+	for name, value := range map[string]*float64{"alpha": &params.Alpha, "gamma": &params.Gamma} {
+		v, ok := queryFloat(c, name)
+		if !ok {
+			return
+		}
+		if v != nil {
+			*value = *v
+		}
+	}
+
+	warehouseID, ok := queryInt(c, "warehouse_id")
+	if !ok {
+		return
+	}
+	categoryID, ok := queryInt(c, "category_id")
+	if !ok {
+		return
+	}
+	reorderOnly, ok := queryBool(c, "reorder_only")
+	if !ok {
+		return
+	}
+
+	days, historyDays, defaultLeadTime := intParams["days"], intParams["history_days"], intParams["lead_time_days"]
+	if days < 1 || days > 180 || historyDays < 7 || historyDays > 730 || defaultLeadTime < 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Горизонт прогноза - от 1 до 180 дней, история - от 7 до 730 дней",
+		})
+		return
+	}
+	if err := params.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	rows, err := h.DB.Query(`
+		SELECT w.id, w.name, COALESCE(w.sku, ''), w.quantity, ps.supplier_id, COALESCE(s.name, ''), s.lead_time_days
+		FROM warehouses w
+		LEFT JOIN product_suppliers ps ON ps.warehouse_id = w.id AND ps.is_primary
+		LEFT JOIN suppliers s ON s.id = ps.supplier_id
+		WHERE w.is_active AND ($1::int IS NULL OR w.id = $1) AND `+categoryFilter("w.category_id", 2)+`
+		ORDER BY w.name
+	`, warehouseID, categoryID)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения товаров",
+		})
+		return
+	}
+
+	var items []*models.DemandForecast
+	byID := make(map[int]*models.DemandForecast)
+	for rows.Next() {
+		var item models.DemandForecast
+		var leadTime *int
+		if err := rows.Scan(&item.WarehouseID, &item.Name, &item.SKU, &item.Quantity,
+			&item.SupplierID, &item.SupplierName, &leadTime); err != nil {
+			continue
+		}
+		item.LeadTimeDays = defaultLeadTime
+		if leadTime != nil {
+			item.LeadTimeDays = *leadTime
+		}
+		items = append(items, &item)
+		byID[item.WarehouseID] = &item
+	}
+	rows.Close()
+
+	// История - последние полные дни, сегодняшние продажи еще не закончились
+	now := time.Now()
+	historyEnd := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	historyStart := historyEnd.AddDate(0, 0, -historyDays)
+
+	rows, err = h.DB.Query(`
+		SELECT warehouse_id, sale_date::date - $1::date, SUM(quantity)
+		FROM sales
+		WHERE sale_date >= $1 AND sale_date < $2 AND ($3::int IS NULL OR warehouse_id = $3)
+		GROUP BY 1, 2
+	`, historyStart, historyEnd, warehouseID)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения истории продаж",
+		})
+		return
+	}
+	defer rows.Close()
+
+	history := make(map[int][]float64, len(items))
+	for rows.Next() {
+		var id, day int
+		var quantity float64
+		if err := rows.Scan(&id, &day, &quantity); err != nil {
+			continue
+		}
+		if byID[id] == nil || day < 0 || day >= historyDays {
+			continue
+		}
+		if history[id] == nil {
+			history[id] = make([]float64, historyDays)
+		}
+		history[id][day] = quantity
+	}
+
+	result := []*models.DemandForecast{}
+	for _, item := range items {
+		sales := history[item.WarehouseID]
+		if sales == nil {
+			sales = make([]float64, historyDays)
+		}
+
+		reorder := analytics.SuggestReorder(sales, item.Quantity, item.LeadTimeDays, days, params)
+		if reorderOnly != nil && *reorderOnly && reorder.Quantity == 0 {
+			continue
+		}
+
+		var total float64
+		for _, v := range sales {
+			total += v
+		}
+		item.AverageDaily = round2(total / float64(historyDays))
+
+		item.Forecast = analytics.Forecast(sales, days, params)
+		for i, v := range item.Forecast {
+			item.ForecastTotal += v
+			item.Forecast[i] = round2(v)
+		}
+		item.ForecastTotal = round2(item.ForecastTotal)
+
+		item.LeadTimeDemand = reorder.LeadTimeDemand
+		item.SafetyStock = reorder.SafetyStock
+		item.ReorderPoint = reorder.ReorderPoint
+		item.StockoutDays = reorder.StockoutDay
+		item.SuggestedQuantity = reorder.Quantity
+		result = append(result, item)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].SuggestedQuantity > 0 && result[j].SuggestedQuantity == 0
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"method":        params.Method,
+			"days":          days,
+			"history_start": historyStart.Format("2006-01-02"),
+			"history_end":   historyEnd.AddDate(0, 0, -1).Format("2006-01-02"),
+			"items":         result,
+		},
+	})
+}
+
+// GetSalesHeatmapReport возвращает продажи за период по дням недели и часам для тепловой карты.
+// Время продаж переводится из пояса записи в БД (DATA_TIMEZONE) в пояс магазина (STORE_TIMEZONE),
+// даты периода тоже указываются по времени магазина. Фильтр location_id - продажи локации.
+func (h *ReportsHandler) GetSalesHeatmapReport(c *gin.Context) {
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
+
+	rows, err := h.DB.Query(`
+		WITH local_sales AS (
+			SELECT (sale_date AT TIME ZONE $3) AT TIME ZONE $4 as local_date, quantity, amount
+			FROM sales
+			WHERE sale_date BETWEEN ($1::timestamp AT TIME ZONE $4) AT TIME ZONE $3
+			                    AND ($2::timestamp AT TIME ZONE $4) AT TIME ZONE $3
+			  AND ($5::int IS NULL OR location_id = $5)
+		)
+		SELECT EXTRACT(ISODOW FROM local_date)::int, EXTRACT(HOUR FROM local_date)::int,
+		       COUNT(*), COALESCE(SUM(quantity), 0), COALESCE(SUM(amount), 0)
+		FROM local_sales
+		GROUP BY 1, 2
+	`, startDate, endDate, h.Store.DataTimeZone, h.Store.TimeZone, locationID)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения продаж по часам, проверьте часовые пояса STORE_TIMEZONE и DATA_TIMEZONE",
+		})
+		return
+	}
+	defer rows.Close()
+
+	report := models.SalesHeatmap{
+		TimeZone: h.Store.TimeZone,
+		Weekdays: []string{"Пн", "Вт", "Ср", "Чт", "Пт", "Сб", "Вс"},
+	}
+	for rows.Next() {
+		var weekday, hour int
+		var t models.SalesTotals
+		if err := rows.Scan(&weekday, &hour, &t.Count, &t.Quantity, &t.Revenue); err != nil {
+			continue
+		}
+		day := weekday - 1
+
+		report.Count[day][hour] = t.Count
+		report.Quantity[day][hour] = t.Quantity
+		report.Revenue[day][hour] = round2(t.Revenue)
+
+		for _, totals := range []*models.SalesTotals{&report.ByWeekday[day], &report.ByHour[hour], &report.Total} {
+			totals.Count += t.Count
+			totals.Quantity += t.Quantity
+			totals.Revenue += t.Revenue
+		}
+	}
+
+	for i := range report.ByWeekday {
+		report.ByWeekday[i].Revenue = round2(report.ByWeekday[i].Revenue)
+	}
+	for i := range report.ByHour {
+		report.ByHour[i].Revenue = round2(report.ByHour[i].Revenue)
+	}
+	report.Total.Revenue = round2(report.Total.Revenue)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    report,
+	})
+}
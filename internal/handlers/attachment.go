@@ -0,0 +1,402 @@
+// handlers/attachments.go
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"store_app/internal/config"
+	"store_app/internal/models"
+	"store_app/internal/storage"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttachmentsHandler struct {
+	DB      *sql.DB
+	Storage storage.Storage
+	MaxSize int64
+}
+
+func NewAttachmentsHandler(db *sql.DB) *AttachmentsHandler {
+	cfg := config.Load().Attachments
+	return &AttachmentsHandler{DB: db, Storage: storage.NewLocalStorage(cfg.Dir), MaxSize: cfg.MaxSize}
+}
+
+// allowedAttachmentTypes - типы файлов, определяемые по содержимому, которые можно загрузить
+var allowedAttachmentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+}
+
+// attachmentOwner описывает запись, к которой прикладываются файлы
+type attachmentOwner struct {
+	kind     string // charge, sale или lot
+	column   string // колонка ссылки в attachments
+	table    string
+	cashier  string // выражение с кассиром владельца, пусто - владелец доступен всем
+	notFound string
+}
+
+var (
+	chargeAttachments = attachmentOwner{"charge", "charge_id", "charges", "", "Расход не найден"}
+	saleAttachments   = attachmentOwner{"sale", "sale_id", "sales", "COALESCE(cashier, '')", "Продажа не найдена"}
+	lotAttachments    = attachmentOwner{"lot", "lot_id", "lots", "", "Партия не найдена"}
+)
+
+const attachmentColumns = `id,
+        CASE WHEN charge_id IS NOT NULL THEN 'charge' WHEN sale_id IS NOT NULL THEN 'sale' ELSE 'lot' END,
+        COALESCE(charge_id, sale_id, lot_id), file_name, content_type, size, storage_key,
+        COALESCE(uploaded_by, ''), created_at`
+
+func scanAttachment(row interface{ Scan(...interface{}) error }, a *models.Attachment) error {
+	return row.Scan(&a.ID, &a.OwnerType, &a.OwnerID, &a.FileName, &a.ContentType, &a.Size, &a.StorageKey,
+		&a.UploadedBy, &a.CreatedAt)
+}
+
+// checkAttachmentOwner проверяет, что владелец существует и доступен пользователю:
+// вложения продажи видят только администраторы и кассир продажи
+func checkAttachmentOwner(q queryer, c *gin.Context, owner attachmentOwner, id int) *apiError {
+	cashier := "''"
+	if owner.cashier != "" {
+		cashier = owner.cashier
+	}
+
+	var ownerCashier string
+	err := q.QueryRow("SELECT "+cashier+" FROM "+owner.table+" WHERE id = $1", id).Scan(&ownerCashier)
+	if err == sql.ErrNoRows {
+		return &apiError{http.StatusNotFound, owner.notFound}
+	}
+	if err != nil {
+		return &apiError{http.StatusInternalServerError, "Ошибка проверки доступа к вложениям"}
+	}
+
+	if owner.cashier != "" && !isAdmin(c) && ownerCashier != c.GetString("username") {
+		return &apiError{http.StatusForbidden, "Недостаточно прав для доступа к вложениям"}
+	}
+	return nil
+}
+
+// newStorageKey возвращает случайный ключ файла в каталоге владельца
+func newStorageKey(owner attachmentOwner) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return owner.kind + "/" + hex.EncodeToString(b), nil
+}
+
+// GetChargeAttachments возвращает вложения расхода
+func (h *AttachmentsHandler) GetChargeAttachments(c *gin.Context) {
+	h.listAttachments(c, chargeAttachments)
+}
+
+// UploadChargeAttachment прикладывает файл к расходу
+func (h *AttachmentsHandler) UploadChargeAttachment(c *gin.Context) {
+	h.uploadAttachment(c, chargeAttachments)
+}
+
+// GetSaleAttachments возвращает вложения продажи
+func (h *AttachmentsHandler) GetSaleAttachments(c *gin.Context) {
+	h.listAttachments(c, saleAttachments)
+}
+
+// UploadSaleAttachment прикладывает файл к продаже
+func (h *AttachmentsHandler) UploadSaleAttachment(c *gin.Context) {
+	h.uploadAttachment(c, saleAttachments)
+}
+
+// GetLotAttachments возвращает вложения приемки партии (накладные поставщика)
+func (h *AttachmentsHandler) GetLotAttachments(c *gin.Context) {
+	h.listAttachments(c, lotAttachments)
+}
+
+// UploadLotAttachment прикладывает файл к приемке партии
+func (h *AttachmentsHandler) UploadLotAttachment(c *gin.Context) {
+	h.uploadAttachment(c, lotAttachments)
+}
+
+func (h *AttachmentsHandler) listAttachments(c *gin.Context, owner attachmentOwner) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID",
+		})
+		return
+	}
+
+	if apiErr := checkAttachmentOwner(h.DB, c, owner, id); apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	rows, err := h.DB.Query("SELECT "+attachmentColumns+" FROM attachments WHERE "+owner.column+" = $1 ORDER BY id", id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения вложений",
+		})
+		return
+	}
+	defer rows.Close()
+
+	attachments := []models.Attachment{}
+	for rows.Next() {
+		var a models.Attachment
+		if err := scanAttachment(rows, &a); err != nil {
+			continue
+		}
+		attachments = append(attachments, a)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    attachments,
+	})
+}
+
+// uploadAttachment принимает файл из поля file формы multipart/form-data. Тип файла
+// определяется по содержимому, а не по имени и заголовкам клиента.
+func (h *AttachmentsHandler) uploadAttachment(c *gin.Context, owner attachmentOwner) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID",
+		})
+		return
+	}
+
+	// Запас на заголовки и остальные поля формы
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.MaxSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, models.APIResponse{
+				Success: false,
+				Error:   "Файл слишком большой",
+			})
+		} else {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "Не передан файл (поле file)",
+			})
+		}
+		return
+	}
+
+	if fileHeader.Size > h.MaxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, models.APIResponse{
+			Success: false,
+			Error:   "Файл слишком большой",
+		})
+		return
+	}
+
+	if apiErr := checkAttachmentOwner(h.DB, c, owner, id); apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка чтения файла",
+		})
+		return
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка чтения файла",
+		})
+		return
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	if !allowedAttachmentTypes[contentType] {
+		c.JSON(http.StatusUnsupportedMediaType, models.APIResponse{
+			Success: false,
+			Error:   "Недопустимый тип файла, разрешены PDF и изображения (JPEG, PNG, GIF, WebP)",
+		})
+		return
+	}
+
+	key, err := newStorageKey(owner)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка сохранения файла",
+		})
+		return
+	}
+
+	size, err := h.Storage.Save(key, io.MultiReader(bytes.NewReader(head), file))
+	if err != nil {
+		log.Printf("Failed to save attachment %s: %v", key, err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка сохранения файла",
+		})
+		return
+	}
+
+	fileName := []rune(filepath.Base(fileHeader.Filename))
+	if len(fileName) > 255 {
+		fileName = fileName[len(fileName)-255:]
+	}
+
+	var a models.Attachment
+	err = scanAttachment(h.DB.QueryRow(`
+        INSERT INTO attachments (`+owner.column+`, file_name, content_type, size, storage_key, uploaded_by)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING `+attachmentColumns,
+		id, string(fileName), contentType, size, key, c.GetString("username"),
+	), &a)
+
+	if err != nil {
+		h.Storage.Delete(key)
+		if isForeignKeyViolation(err) {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   owner.notFound,
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка сохранения вложения",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    a,
+		Message: "Файл успешно загружен",
+	})
+}
+
+// loadAttachment загружает вложение и проверяет доступ пользователя к его владельцу
+func (h *AttachmentsHandler) loadAttachment(c *gin.Context, a *models.Attachment) *apiError {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return &apiError{http.StatusBadRequest, "Неверный ID вложения"}
+	}
+
+	err = scanAttachment(h.DB.QueryRow("SELECT "+attachmentColumns+" FROM attachments WHERE id = $1", id), a)
+	if err == sql.ErrNoRows {
+		return &apiError{http.StatusNotFound, "Вложение не найдено"}
+	}
+	if err != nil {
+		return &apiError{http.StatusInternalServerError, "Ошибка получения вложения"}
+	}
+
+	owner := chargeAttachments
+	switch a.OwnerType {
+	case saleAttachments.kind:
+		owner = saleAttachments
+	case lotAttachments.kind:
+		owner = lotAttachments
+	}
+	return checkAttachmentOwner(h.DB, c, owner, a.OwnerID)
+}
+
+// DownloadAttachment отдает файл вложения
+func (h *AttachmentsHandler) DownloadAttachment(c *gin.Context) {
+	var a models.Attachment
+	if apiErr := h.loadAttachment(c, &a); apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	file, err := h.Storage.Open(a.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Файл вложения не найден в хранилище",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка чтения файла",
+			})
+		}
+		return
+	}
+	defer file.Close()
+
+	// Тип файла проверен при загрузке, браузер не должен угадывать его заново
+	c.DataFromReader(http.StatusOK, a.Size, a.ContentType, file, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": a.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DeleteAttachment удаляет вложение. Удалить может администратор или загрузивший файл пользователь.
+func (h *AttachmentsHandler) DeleteAttachment(c *gin.Context) {
+	var a models.Attachment
+	if apiErr := h.loadAttachment(c, &a); apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	if !isAdmin(c) && a.UploadedBy != c.GetString("username") {
+		c.JSON(http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Error:   "Удалить вложение может только администратор или загрузивший его пользователь",
+		})
+		return
+	}
+
+	if _, err := h.DB.Exec("DELETE FROM attachments WHERE id = $1", a.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка удаления вложения",
+		})
+		return
+	}
+
+	// Запись уже удалена, оставшийся файл не мешает работе
+	if err := h.Storage.Delete(a.StorageKey); err != nil {
+		log.Printf("Failed to delete attachment file %s: %v", a.StorageKey, err)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Вложение успешно удалено",
+	})
+}
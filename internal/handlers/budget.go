@@ -0,0 +1,332 @@
+// handlers/budgets.go
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"store_app/internal/models"
+	"store_app/internal/notify"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BudgetsHandler struct {
+	DB *sql.DB
+}
+
+func NewBudgetsHandler(db *sql.DB) *BudgetsHandler {
+	return &BudgetsHandler{DB: db}
+}
+
+type budgetRequest struct {
+	ExpenseItemID int     `json:"expense_item_id" binding:"required"`
+	Year          int     `json:"year" binding:"required,min=2000,max=2100"`
+	Month         int     `json:"month" binding:"required,min=1,max=12"`
+	Amount        float64 `json:"amount" binding:"min=0"`
+	HardLimit     bool    `json:"hard_limit"`
+}
+
+const budgetColumns = `id, expense_item_id, year, month, amount, hard_limit, COALESCE(created_by, ''), created_at`
+
+func scanBudget(row interface{ Scan(...interface{}) error }, b *models.Budget) error {
+	return row.Scan(&b.ID, &b.ExpenseItemID, &b.Year, &b.Month, &b.Amount, &b.HardLimit, &b.CreatedBy, &b.CreatedAt)
+}
+
+// checkBudget сверяет расходы с бюджетами статьи расхода и всех ее родительских статей за месяц
+// расхода: бюджет статьи покрывает расходы на нее и на все ее подстатьи. Вызывается в транзакции
+// после записи расхода charge, previous - прежние данные изменяемого расхода (nil при создании).
+// Ожидающие утверждения расходы резервируют бюджет наравне с утвержденными.
+// Возвращает признак превышения бюджета и события по бюджетам, которые превысила именно эта запись.
+// Для бюджета с hard_limit увеличение расходов сверх бюджета - ошибка, транзакцию нужно откатить.
+func checkBudget(q queryer, charge models.Charge, previous *models.Charge) (bool, []notify.Event, *apiError) {
+	type budget struct {
+		models.Budget
+		itemName string
+	}
+
+	// Блокировка бюджетов выстраивает в очередь одновременные расходы по статьям,
+	// иначе каждый из них мог бы уложиться в бюджет по отдельности
+	rows, err := q.Query(`
+        WITH RECURSIVE ancestors AS (
+            SELECT id, parent_id FROM expense_items WHERE id = $1
+            UNION ALL
+            SELECT e.id, e.parent_id FROM expense_items e JOIN ancestors a ON e.id = a.parent_id
+        )
+        SELECT b.id, b.expense_item_id, b.year, b.month, b.amount, b.hard_limit, e.name
+        FROM budgets b
+        JOIN ancestors a ON a.id = b.expense_item_id
+        JOIN expense_items e ON e.id = b.expense_item_id
+        WHERE b.year = EXTRACT(YEAR FROM $2::timestamp) AND b.month = EXTRACT(MONTH FROM $2::timestamp)
+        ORDER BY b.id
+        FOR UPDATE OF b
+    `, charge.ExpenseItemID, charge.ChargeDate)
+	if err != nil {
+		return false, nil, &apiError{http.StatusInternalServerError, "Ошибка проверки бюджета"}
+	}
+
+	var budgets []budget
+	for rows.Next() {
+		var b budget
+		if err := rows.Scan(&b.ID, &b.ExpenseItemID, &b.Year, &b.Month, &b.Amount, &b.HardLimit, &b.itemName); err != nil {
+			rows.Close()
+			return false, nil, &apiError{http.StatusInternalServerError, "Ошибка проверки бюджета"}
+		}
+		budgets = append(budgets, b)
+	}
+	rows.Close()
+
+	if len(budgets) == 0 {
+		return false, nil, nil
+	}
+
+	// Прежняя сумма расхода уже входила в расходы бюджетов над прежней статьей в том же месяце
+	var previousItems map[int]bool
+	if previous != nil && previous.ChargeDate.Year() == charge.ChargeDate.Year() &&
+		previous.ChargeDate.Month() == charge.ChargeDate.Month() {
+		if previousItems, err = expenseItemAncestors(q, previous.ExpenseItemID); err != nil {
+			return false, nil, &apiError{http.StatusInternalServerError, "Ошибка проверки бюджета"}
+		}
+	}
+
+	exceeded := false
+	var events []notify.Event
+	for _, b := range budgets {
+		var spent float64
+		err := q.QueryRow(`
+            SELECT COALESCE(SUM(amount), 0)
+            FROM charges
+            WHERE `+expenseItemFilter("expense_item_id", 1)+` AND status <> 'rejected'
+              AND charge_date >= make_date($2, $3, 1) AND charge_date < make_date($2, $3, 1) + INTERVAL '1 month'
+        `, b.ExpenseItemID, b.Year, b.Month).Scan(&spent)
+		if err != nil {
+			return false, nil, &apiError{http.StatusInternalServerError, "Ошибка проверки бюджета"}
+		}
+
+		spent = round2(spent)
+		if spent <= b.Amount {
+			continue
+		}
+		exceeded = true
+
+		added := charge.Amount
+		if previousItems[b.ExpenseItemID] {
+			added -= previous.Amount
+		}
+
+		// Запись, не увеличившая расходы по бюджету, не блокируется и не оповещает повторно
+		if added <= 0 {
+			continue
+		}
+
+		message := fmt.Sprintf("Превышен бюджет статьи \"%s\" на %02d.%d: израсходовано %.2f из %.2f",
+			b.itemName, b.Month, b.Year, spent, b.Amount)
+
+		if b.HardLimit {
+			return true, nil, &apiError{http.StatusUnprocessableEntity, message}
+		}
+
+		if spent-added > b.Amount {
+			continue
+		}
+
+		events = append(events, notify.Event{
+			Type:    notify.EventBudgetExceeded,
+			Message: message,
+			Payload: gin.H{
+				"budget_id":       b.ID,
+				"expense_item_id": b.ExpenseItemID,
+				"charge_id":       charge.ID,
+				"year":            b.Year,
+				"month":           b.Month,
+				"budget":          b.Amount,
+				"spent":           spent,
+			},
+		})
+	}
+
+	return exceeded, events, nil
+}
+
+// GetBudgets возвращает бюджеты, поддерживает фильтры year, month и expense_item_id
+func (h *BudgetsHandler) GetBudgets(c *gin.Context) {
+	year, ok := queryInt(c, "year")
+	if !ok {
+		return
+	}
+	month, ok := queryInt(c, "month")
+	if !ok {
+		return
+	}
+	expenseItemID, ok := queryInt(c, "expense_item_id")
+	if !ok {
+		return
+	}
+
+	rows, err := h.DB.Query(`
+        SELECT `+budgetColumns+`
+        FROM budgets
+        WHERE ($1::int IS NULL OR year = $1)
+          AND ($2::int IS NULL OR month = $2)
+          AND ($3::int IS NULL OR expense_item_id = $3)
+        ORDER BY year DESC, month DESC, expense_item_id
+    `, year, month, expenseItemID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения бюджетов",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var budgets []models.Budget
+	for rows.Next() {
+		var b models.Budget
+		if err := scanBudget(rows, &b); err != nil {
+			continue
+		}
+		budgets = append(budgets, b)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    budgets,
+	})
+}
+
+// CreateBudget задает бюджет статьи расходов на месяц
+func (h *BudgetsHandler) CreateBudget(c *gin.Context) {
+	var req budgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	var b models.Budget
+	err := scanBudget(h.DB.QueryRow(`
+        INSERT INTO budgets (expense_item_id, year, month, amount, hard_limit, created_by)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING `+budgetColumns,
+		req.ExpenseItemID, req.Year, req.Month, req.Amount, req.HardLimit, c.GetString("username"),
+	), &b)
+
+	if err != nil {
+		h.budgetWriteError(c, err, "Ошибка создания бюджета")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    b,
+		Message: "Бюджет успешно создан",
+	})
+}
+
+// UpdateBudget изменяет бюджет. Уже созданные расходы не пересматриваются.
+func (h *BudgetsHandler) UpdateBudget(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID бюджета",
+		})
+		return
+	}
+
+	var req budgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	var b models.Budget
+	err = scanBudget(h.DB.QueryRow(`
+        UPDATE budgets
+        SET expense_item_id = $1, year = $2, month = $3, amount = $4, hard_limit = $5
+        WHERE id = $6
+        RETURNING `+budgetColumns,
+		req.ExpenseItemID, req.Year, req.Month, req.Amount, req.HardLimit, id,
+	), &b)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Бюджет не найден",
+			})
+		} else {
+			h.budgetWriteError(c, err, "Ошибка обновления бюджета")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    b,
+		Message: "Бюджет успешно обновлен",
+	})
+}
+
+func (h *BudgetsHandler) budgetWriteError(c *gin.Context, err error, message string) {
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Error:   "Бюджет статьи на этот месяц уже задан",
+		})
+		return
+	}
+	if isForeignKeyViolation(err) {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error:   "Статья расходов не найдена",
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, models.APIResponse{
+		Success: false,
+		Error:   message,
+	})
+}
+
+// DeleteBudget удаляет бюджет
+func (h *BudgetsHandler) DeleteBudget(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID бюджета",
+		})
+		return
+	}
+
+	result, err := h.DB.Exec("DELETE FROM budgets WHERE id = $1", id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка удаления бюджета",
+		})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error:   "Бюджет не найден",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Бюджет успешно удален",
+	})
+}
@@ -0,0 +1,302 @@
+// handlers/categories.go
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"store_app/internal/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoriesHandler struct {
+	DB *sql.DB
+}
+
+func NewCategoriesHandler(db *sql.DB) *CategoriesHandler {
+	return &CategoriesHandler{DB: db}
+}
+
+type categoryRequest struct {
+	Name     string `json:"name" binding:"required"`
+	ParentID *int   `json:"parent_id"`
+}
+
+// categoryFilter возвращает SQL-условие "колонка входит в категорию с номером параметра $param
+// или в любую из ее подкатегорий". Если параметр NULL, условие выполняется всегда.
+func categoryFilter(column string, param int) string {
+	return subtreeFilter("categories", column, param)
+}
+
+// subtreeFilter - то же условие для любой таблицы-дерева со ссылкой parent_id на себя
+func subtreeFilter(table, column string, param int) string {
+	return fmt.Sprintf(`($%[3]d::int IS NULL OR %[2]s IN (
+            WITH RECURSIVE subtree AS (
+                SELECT id FROM %[1]s WHERE id = $%[3]d
+                UNION ALL
+                SELECT c.id FROM %[1]s c JOIN subtree s ON c.parent_id = s.id
+            )
+            SELECT id FROM subtree
+        ))`, table, column, param)
+}
+
+// GetCategories возвращает категории списком, с параметром tree=true - деревом
+func (h *CategoriesHandler) GetCategories(c *gin.Context) {
+	rows, err := h.DB.Query(`
+        SELECT id, name, parent_id
+        FROM categories
+        ORDER BY name
+    `)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения категорий",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var categories []*models.Category
+	for rows.Next() {
+		var cat models.Category
+		if err := rows.Scan(&cat.ID, &cat.Name, &cat.ParentID); err != nil {
+			continue
+		}
+		categories = append(categories, &cat)
+	}
+
+	if c.Query("tree") == "true" {
+		byID := make(map[int]*models.Category, len(categories))
+		for _, cat := range categories {
+			byID[cat.ID] = cat
+		}
+
+		var roots []*models.Category
+		for _, cat := range categories {
+			if cat.ParentID != nil {
+				if parent, ok := byID[*cat.ParentID]; ok {
+					parent.Children = append(parent.Children, cat)
+					continue
+				}
+			}
+			roots = append(roots, cat)
+		}
+		categories = roots
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    categories,
+	})
+}
+
+// GetCategory возвращает категорию по ID
+func (h *CategoriesHandler) GetCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID категории",
+		})
+		return
+	}
+
+	var cat models.Category
+	err = h.DB.QueryRow(
+		"SELECT id, name, parent_id FROM categories WHERE id = $1",
+		id,
+	).Scan(&cat.ID, &cat.Name, &cat.ParentID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Категория не найдена",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка получения категории",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    cat,
+	})
+}
+
+// CreateCategory создает новую категорию
+func (h *CategoriesHandler) CreateCategory(c *gin.Context) {
+	var req categoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	var cat models.Category
+	err := h.DB.QueryRow(
+		"INSERT INTO categories (name, parent_id) VALUES ($1, $2) RETURNING id, name, parent_id",
+		req.Name, req.ParentID,
+	).Scan(&cat.ID, &cat.Name, &cat.ParentID)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Error:   "Категория с таким названием уже существует",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка создания категории",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    cat,
+		Message: "Категория успешно создана",
+	})
+}
+
+// UpdateCategory переименовывает категорию или переносит ее к другому родителю
+func (h *CategoriesHandler) UpdateCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID категории",
+		})
+		return
+	}
+
+	var req categoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	// Родитель не может быть самой категорией или ее потомком
+	if req.ParentID != nil {
+		var cycle bool
+		err = h.DB.QueryRow(`
+            WITH RECURSIVE subtree AS (
+                SELECT id FROM categories WHERE id = $1
+                UNION ALL
+                SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+            )
+            SELECT EXISTS(SELECT 1 FROM subtree WHERE id = $2)
+        `, id, *req.ParentID).Scan(&cycle)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка обновления категории",
+			})
+			return
+		}
+		if cycle {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "Категорию нельзя вложить в саму себя или в ее подкатегорию",
+			})
+			return
+		}
+	}
+
+	var cat models.Category
+	err = h.DB.QueryRow(
+		"UPDATE categories SET name = $1, parent_id = $2 WHERE id = $3 RETURNING id, name, parent_id",
+		req.Name, req.ParentID, id,
+	).Scan(&cat.ID, &cat.Name, &cat.ParentID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Категория не найдена",
+			})
+		} else if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Error:   "Категория с таким названием уже существует",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка обновления категории",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    cat,
+		Message: "Категория успешно обновлена",
+	})
+}
+
+// DeleteCategory удаляет категорию без подкатегорий, товары остаются без категории
+func (h *CategoriesHandler) DeleteCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID категории",
+		})
+		return
+	}
+
+	var hasChildren bool
+	err = h.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM categories WHERE parent_id = $1)", id).Scan(&hasChildren)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка удаления категории",
+		})
+		return
+	}
+	if hasChildren {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Error:   "Нельзя удалить категорию с подкатегориями",
+		})
+		return
+	}
+
+	result, err := h.DB.Exec("DELETE FROM categories WHERE id = $1", id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка удаления категории",
+		})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error:   "Категория не найдена",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Категория успешно удалена",
+	})
+}
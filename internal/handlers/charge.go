@@ -3,8 +3,12 @@ package handlers
 
 import (
 	"database/sql"
+	"io"
 	"net/http"
+	"store_app/internal/config"
+	"store_app/internal/ledger"
 	"store_app/internal/models"
+	"store_app/internal/notify"
 	"strconv"
 	"time"
 
@@ -12,20 +16,136 @@ import (
 )
 
 type ChargesHandler struct {
-	DB *sql.DB
+	DB       *sql.DB
+	Notifier notify.Notifier
 }
 
 func NewChargesHandler(db *sql.DB) *ChargesHandler {
-	return &ChargesHandler{DB: db}
+	return &ChargesHandler{DB: db, Notifier: notify.New(db, config.Load().Alerts)}
 }
 
-// GetCharges возвращает все расходы
+// chargeRequest - данные расхода при создании и полном обновлении.
+// charge_date принимается как дата (YYYY-MM-DD) или дата со временем (RFC 3339),
+// без нее расход создается текущим моментом.
+type chargeRequest struct {
+	ExpenseItemID  int     `json:"expense_item_id" binding:"required"`
+	SupplierID     *int    `json:"supplier_id"`
+	Amount         float64 `json:"amount" binding:"required,min=0"`
+	ChargeDate     string  `json:"charge_date"`
+	Description    string  `json:"description"`
+	DocumentNumber string  `json:"document_number" binding:"max=64"`
+}
+
+// chargePatchRequest - частичное обновление расхода, переданные поля заменяют текущие
+type chargePatchRequest struct {
+	ExpenseItemID  *int     `json:"expense_item_id"`
+	SupplierID     *int     `json:"supplier_id"`
+	Amount         *float64 `json:"amount" binding:"omitempty,min=0"`
+	ChargeDate     *string  `json:"charge_date"`
+	Description    *string  `json:"description"`
+	DocumentNumber *string  `json:"document_number" binding:"omitempty,max=64"`
+}
+
+const chargeColumns = `id, expense_item_id, supplier_id, amount, charge_date, description, document_number,
+        status, COALESCE(reviewed_by, ''), reviewed_at, review_comment`
+
+func scanCharge(row interface{ Scan(...interface{}) error }, ch *models.Charge) error {
+	return row.Scan(&ch.ID, &ch.ExpenseItemID, &ch.SupplierID, &ch.Amount, &ch.ChargeDate, &ch.Description,
+		&ch.DocumentNumber, &ch.Status, &ch.ReviewedBy, &ch.ReviewedAt, &ch.ReviewComment)
+}
+
+// approvalThreshold возвращает порог утверждения расходов статьи: ее собственный или ближайшей
+// родительской статьи. nil - расходы статьи утверждения не требуют.
+func approvalThreshold(q queryer, expenseItemID int) (*float64, error) {
+	var threshold *float64
+	err := q.QueryRow(`
+        WITH RECURSIVE ancestors AS (
+            SELECT id, parent_id, approval_threshold, 0 as depth FROM expense_items WHERE id = $1
+            UNION ALL
+            SELECT e.id, e.parent_id, e.approval_threshold, a.depth + 1
+            FROM expense_items e JOIN ancestors a ON e.id = a.parent_id
+        )
+        SELECT approval_threshold FROM ancestors
+        WHERE approval_threshold IS NOT NULL
+        ORDER BY depth
+        LIMIT 1
+    `, expenseItemID).Scan(&threshold)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return threshold, err
+}
+
+// insertCharge записывает новый расход по общим для API и фоновых задач правилам: расход
+// с суммой выше порога утверждения статьи ожидает утверждения, расходы сверяются с бюджетами
+// (жесткий лимит - ошибка), утвержденный расход проводится. Вызывается в транзакции после
+// проверки учетного периода. Ошибки правил возвращаются как *apiError, ошибка записи - как есть.
+func insertCharge(q queryer, req models.Charge) (models.Charge, bool, []notify.Event, error) {
+	var charge models.Charge
+	threshold, err := approvalThreshold(q, req.ExpenseItemID)
+	if err != nil {
+		return charge, false, nil, &apiError{http.StatusInternalServerError, "Ошибка создания расхода"}
+	}
+
+	status := "approved"
+	if threshold != nil && req.Amount > *threshold {
+		status = "pending"
+	}
+
+	err = scanCharge(q.QueryRow(
+		`INSERT INTO charges (expense_item_id, supplier_id, amount, charge_date, description, document_number, status)
+         VALUES ($1, $2, $3, $4, $5, $6, $7)
+         RETURNING `+chargeColumns,
+		req.ExpenseItemID, req.SupplierID, req.Amount, req.ChargeDate, req.Description, req.DocumentNumber, status,
+	), &charge)
+	if err != nil {
+		return charge, false, nil, err
+	}
+
+	exceeded, events, apiErr := checkBudget(q, charge, nil)
+	if apiErr != nil {
+		return charge, false, nil, apiErr
+	}
+
+	if err := ledger.RepostCharge(q, charge.ID); err != nil {
+		return charge, false, nil, &apiError{http.StatusInternalServerError, "Ошибка проводки расхода"}
+	}
+	return charge, exceeded, events, nil
+}
+
+// InsertCharge создает расход по тем же правилам, что и API: для фоновых задач
+func InsertCharge(tx *sql.Tx, charge models.Charge) (models.Charge, []notify.Event, error) {
+	charge, _, events, err := insertCharge(tx, charge)
+	return charge, events, err
+}
+
+// parseChargeDate разбирает дату расхода. Дата без времени означает начало дня.
+func parseChargeDate(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// GetCharges возвращает все расходы, поддерживает фильтры status, supplier_id и expense_item_id
+// (по статье вместе с ее подстатьями)
 func (h *ChargesHandler) GetCharges(c *gin.Context) {
+	supplierID, ok := queryInt(c, "supplier_id")
+	if !ok {
+		return
+	}
+	expenseItemID, ok := queryInt(c, "expense_item_id")
+	if !ok {
+		return
+	}
+
 	rows, err := h.DB.Query(`
-        SELECT c.id, c.expense_item_id, c.amount, c.charge_date
-        FROM charges c
-        ORDER BY c.charge_date DESC
-    `)
+        SELECT `+chargeColumns+`
+        FROM charges
+        WHERE ($1::int IS NULL OR supplier_id = $1) AND `+expenseItemFilter("expense_item_id", 2)+`
+          AND ($3 = '' OR status = $3)
+        ORDER BY charge_date DESC
+    `, supplierID, expenseItemID, c.Query("status"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
@@ -38,7 +158,7 @@ func (h *ChargesHandler) GetCharges(c *gin.Context) {
 	var charges []models.Charge
 	for rows.Next() {
 		var charge models.Charge
-		if err := rows.Scan(&charge.ID, &charge.ExpenseItemID, &charge.Amount, &charge.ChargeDate); err != nil {
+		if err := scanCharge(rows, &charge); err != nil {
 			continue
 		}
 		charges = append(charges, charge)
@@ -50,13 +170,109 @@ func (h *ChargesHandler) GetCharges(c *gin.Context) {
 	})
 }
 
-// CreateCharge создает новый расход
+// CreateCharge создает новый расход, в том числе задним числом в пределах открытого периода.
+// Расход выше порога утверждения статьи создается в статусе pending и ждет решения администратора.
+// Расход сверх бюджета статьи отмечается в ответе, а при жестком бюджете не создается.
 func (h *ChargesHandler) CreateCharge(c *gin.Context) {
-	var req struct {
-		ExpenseItemID int     `json:"expense_item_id" binding:"required"`
-		Amount        float64 `json:"amount" binding:"required,min=0"`
+	var req chargeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	chargeDate := time.Now()
+	if req.ChargeDate != "" {
+		var err error
+		if chargeDate, err = parseChargeDate(req.ChargeDate); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "Неверный формат даты расхода",
+			})
+			return
+		}
+	}
+
+	if apiErr := checkSupplier(h.DB, req.SupplierID); apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка начала транзакции",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	if apiErr := ensurePeriodOpen(tx, chargeDate); apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	charge, exceeded, budgetEvents, err := insertCharge(tx, models.Charge{
+		ExpenseItemID:  req.ExpenseItemID,
+		SupplierID:     req.SupplierID,
+		Amount:         req.Amount,
+		ChargeDate:     chargeDate,
+		Description:    req.Description,
+		DocumentNumber: req.DocumentNumber,
+	})
+	if apiErr, ok := err.(*apiError); ok {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+	if err != nil {
+		h.chargeWriteError(c, err, "Ошибка создания расхода")
+		return
 	}
 
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка коммита транзакции",
+		})
+		return
+	}
+
+	for _, e := range budgetEvents {
+		notify.Send(h.Notifier, e)
+	}
+
+	charge.BudgetExceeded = exceeded
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    charge,
+		Message: "Расход успешно создан",
+	})
+}
+
+// UpdateCharge полностью заменяет данные расхода (PUT)
+func (h *ChargesHandler) UpdateCharge(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID расхода",
+		})
+		return
+	}
+
+	var req chargeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
@@ -65,35 +281,339 @@ func (h *ChargesHandler) CreateCharge(c *gin.Context) {
 		return
 	}
 
-	var chargeID int
-	err := h.DB.QueryRow(
-		"INSERT INTO charges (expense_item_id, amount, charge_date) VALUES ($1, $2, $3) RETURNING id",
-		req.ExpenseItemID, req.Amount, time.Now(),
-	).Scan(&chargeID)
+	h.updateCharge(c, id, chargePatchRequest{
+		ExpenseItemID:  &req.ExpenseItemID,
+		SupplierID:     req.SupplierID,
+		Amount:         &req.Amount,
+		ChargeDate:     &req.ChargeDate,
+		Description:    &req.Description,
+		DocumentNumber: &req.DocumentNumber,
+	}, true)
+}
 
+// PatchCharge изменяет только переданные поля расхода (PATCH)
+func (h *ChargesHandler) PatchCharge(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID расхода",
+		})
+		return
+	}
+
+	var req chargePatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	h.updateCharge(c, id, req, false)
+}
+
+// updateCharge сохраняет изменения расхода. При replace пустой supplier_id снимает поставщика,
+// а пустая дата оставляет прежнюю. Увеличение расходов статьи сверх бюджета проверяется так же,
+// как при создании расхода. Отклоненный расход и расход закрытого периода изменить нельзя.
+func (h *ChargesHandler) updateCharge(c *gin.Context, id int, req chargePatchRequest, replace bool) {
+	var chargeDate *time.Time
+	if req.ChargeDate != nil && *req.ChargeDate != "" {
+		d, err := parseChargeDate(*req.ChargeDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "Неверный формат даты расхода",
+			})
+			return
+		}
+		chargeDate = &d
+	}
+
+	if apiErr := checkSupplier(h.DB, req.SupplierID); apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	tx, err := h.DB.Begin()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   "Ошибка создания расхода",
+			Error:   "Ошибка начала транзакции",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	var previous models.Charge
+	err = tx.QueryRow(
+		"SELECT expense_item_id, amount, charge_date, status FROM charges WHERE id = $1 FOR UPDATE", id,
+	).Scan(&previous.ExpenseItemID, &previous.Amount, &previous.ChargeDate, &previous.Status)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Расход не найден",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка обновления расхода",
+			})
+		}
+		return
+	}
+
+	if previous.Status == "rejected" {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Error:   "Отклоненный расход изменить нельзя",
+		})
+		return
+	}
+
+	// Расход нельзя изменить в закрытом периоде и нельзя перенести в закрытый период
+	apiErr := ensurePeriodOpen(tx, previous.ChargeDate)
+	if apiErr == nil && chargeDate != nil {
+		apiErr = ensurePeriodOpen(tx, *chargeDate)
+	}
+	if apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
 		})
 		return
 	}
 
-	// Получаем созданный расход для ответа
 	var charge models.Charge
-	h.DB.QueryRow(
-		"SELECT id, expense_item_id, amount, charge_date FROM charges WHERE id = $1",
-		chargeID,
-	).Scan(&charge.ID, &charge.ExpenseItemID, &charge.Amount, &charge.ChargeDate)
+	err = scanCharge(tx.QueryRow(
+		`UPDATE charges
+         SET expense_item_id = COALESCE($1, expense_item_id),
+             supplier_id = CASE WHEN $2::int IS NOT NULL OR $3 THEN $2 ELSE supplier_id END,
+             amount = COALESCE($4, amount), charge_date = COALESCE($5, charge_date),
+             description = COALESCE($6, description), document_number = COALESCE($7, document_number)
+         WHERE id = $8
+         RETURNING `+chargeColumns,
+		req.ExpenseItemID, req.SupplierID, replace, req.Amount, chargeDate, req.Description, req.DocumentNumber, id,
+	), &charge)
 
-	c.JSON(http.StatusCreated, models.APIResponse{
+	if err != nil {
+		h.chargeWriteError(c, err, "Ошибка обновления расхода")
+		return
+	}
+
+	// Расход выше порога снова уходит на утверждение, если выросла сумма или сменилась статья,
+	// а ожидающий расход, опустившийся до порога, утверждается сам
+	threshold, err := approvalThreshold(tx, charge.ExpenseItemID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка обновления расхода",
+		})
+		return
+	}
+
+	status := "approved"
+	if threshold != nil && charge.Amount > *threshold && (previous.Status == "pending" ||
+		previous.ExpenseItemID != charge.ExpenseItemID || charge.Amount > previous.Amount) {
+		status = "pending"
+	}
+
+	if status != charge.Status {
+		err = scanCharge(tx.QueryRow(`
+            UPDATE charges SET status = $1, reviewed_by = NULL, reviewed_at = NULL, review_comment = ''
+            WHERE id = $2
+            RETURNING `+chargeColumns,
+			status, id,
+		), &charge)
+		if err != nil {
+			h.chargeWriteError(c, err, "Ошибка обновления расхода")
+			return
+		}
+	}
+
+	exceeded, budgetEvents, apiErr := checkBudget(tx, charge, &previous)
+	if apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	if err := ledger.RepostCharge(tx, charge.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка проводки расхода",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка коммита транзакции",
+		})
+		return
+	}
+
+	for _, e := range budgetEvents {
+		notify.Send(h.Notifier, e)
+	}
+
+	charge.BudgetExceeded = exceeded
+	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    charge,
-		Message: "Расход успешно создан",
+		Message: "Расход успешно обновлен",
+	})
+}
+
+// chargeWriteError отвечает на ошибку записи расхода. Нарушения правил, проверяемых
+// триггерами, возвращаются клиенту как есть: превышение максимальной суммы - 422,
+// закрытый учетный период - 409.
+func (h *ChargesHandler) chargeWriteError(c *gin.Context, err error, message string) {
+	if msg, ok := checkViolation(err); ok {
+		c.JSON(http.StatusUnprocessableEntity, models.APIResponse{
+			Success: false,
+			Error:   msg,
+		})
+		return
+	}
+	if msg, ok := raisedException(err); ok {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Error:   msg,
+		})
+		return
+	}
+	if isForeignKeyViolation(err) {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error:   "Статья расходов не найдена",
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, models.APIResponse{
+		Success: false,
+		Error:   message,
+	})
+}
+
+// ApproveCharge утверждает ожидающий расход, после чего он учитывается в отчетах
+func (h *ChargesHandler) ApproveCharge(c *gin.Context) {
+	h.reviewCharge(c, "approved")
+}
+
+// RejectCharge отклоняет ожидающий расход, в отчетах и бюджетах он не учитывается
+func (h *ChargesHandler) RejectCharge(c *gin.Context) {
+	h.reviewCharge(c, "rejected")
+}
+
+// reviewCharge сохраняет решение администратора по расходу в статусе pending
+func (h *ChargesHandler) reviewCharge(c *gin.Context, status string) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID расхода",
+		})
+		return
+	}
+
+	var req struct {
+		Comment string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка начала транзакции",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	var charge models.Charge
+	err = scanCharge(tx.QueryRow(`
+        UPDATE charges
+        SET status = $1, reviewed_by = $2, reviewed_at = CURRENT_TIMESTAMP, review_comment = $3
+        WHERE id = $4 AND status = 'pending'
+        RETURNING `+chargeColumns,
+		status, c.GetString("username"), req.Comment, id,
+	), &charge)
+
+	if err == sql.ErrNoRows {
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM charges WHERE id = $1)", id).Scan(&exists); err == nil && !exists {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Расход не найден",
+			})
+		} else {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Error:   "Расход не ожидает утверждения",
+			})
+		}
+		return
+	}
+	if err != nil {
+		h.chargeWriteError(c, err, "Ошибка утверждения расхода")
+		return
+	}
+
+	// Решение меняет отчеты периода расхода, поэтому в закрытом периоде запрещено
+	if apiErr := ensurePeriodOpen(tx, charge.ChargeDate); apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	if err := ledger.RepostCharge(tx, id); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка проводки расхода",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка коммита транзакции",
+		})
+		return
+	}
+
+	message := "Расход утвержден"
+	if status == "rejected" {
+		message = "Расход отклонен"
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    charge,
+		Message: message,
 	})
 }
 
-// DeleteCharge удаляет расход
+// DeleteCharge удаляет расход, если его период не закрыт
 func (h *ChargesHandler) DeleteCharge(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -104,23 +624,64 @@ func (h *ChargesHandler) DeleteCharge(c *gin.Context) {
 		return
 	}
 
-	result, err := h.DB.Exec("DELETE FROM charges WHERE id = $1", id)
+	tx, err := h.DB.Begin()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   "Ошибка удаления расхода",
+			Error:   "Ошибка начала транзакции",
 		})
 		return
 	}
+	defer tx.Rollback()
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
+	var chargeDate time.Time
+	err = tx.QueryRow("DELETE FROM charges WHERE id = $1 RETURNING charge_date", id).Scan(&chargeDate)
+	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, models.APIResponse{
 			Success: false,
 			Error:   "Расход не найден",
 		})
 		return
 	}
+	if err != nil {
+		if msg, ok := raisedException(err); ok {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Error:   msg,
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка удаления расхода",
+			})
+		}
+		return
+	}
+
+	if apiErr := ensurePeriodOpen(tx, chargeDate); apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	// Проводки удаленного расхода снимаются
+	if err := ledger.RepostCharge(tx, id); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка проводки расхода",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка коммита транзакции",
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
@@ -0,0 +1,331 @@
+// handlers/customers.go
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"store_app/internal/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CustomersHandler struct {
+	DB *sql.DB
+}
+
+func NewCustomersHandler(db *sql.DB) *CustomersHandler {
+	return &CustomersHandler{DB: db}
+}
+
+type customerRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Phone string `json:"phone"`
+	Email string `json:"email" binding:"omitempty,email"`
+	Notes string `json:"notes"`
+}
+
+// GetCustomers возвращает всех покупателей, поддерживает поиск по имени, телефону и email
+func (h *CustomersHandler) GetCustomers(c *gin.Context) {
+	search := c.Query("search")
+
+	rows, err := h.DB.Query(`
+        SELECT id, name, phone, email, notes, created_at
+        FROM customers
+        WHERE $1 = '' OR name ILIKE '%' || $1 || '%' OR phone LIKE '%' || $1 || '%' OR email ILIKE '%' || $1 || '%'
+        ORDER BY id
+    `, search)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения покупателей",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var customers []models.Customer
+	for rows.Next() {
+		var cust models.Customer
+		if err := rows.Scan(&cust.ID, &cust.Name, &cust.Phone, &cust.Email, &cust.Notes, &cust.CreatedAt); err != nil {
+			continue
+		}
+		customers = append(customers, cust)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    customers,
+	})
+}
+
+// GetCustomer возвращает покупателя по ID
+func (h *CustomersHandler) GetCustomer(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID покупателя",
+		})
+		return
+	}
+
+	var cust models.Customer
+	err = h.DB.QueryRow(
+		"SELECT id, name, phone, email, notes, created_at FROM customers WHERE id = $1",
+		id,
+	).Scan(&cust.ID, &cust.Name, &cust.Phone, &cust.Email, &cust.Notes, &cust.CreatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Покупатель не найден",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка получения покупателя",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    cust,
+	})
+}
+
+// CreateCustomer создает нового покупателя
+func (h *CustomersHandler) CreateCustomer(c *gin.Context) {
+	var req customerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	var cust models.Customer
+	err := h.DB.QueryRow(
+		`INSERT INTO customers (name, phone, email, notes) VALUES ($1, $2, $3, $4)
+         RETURNING id, name, phone, email, notes, created_at`,
+		req.Name, req.Phone, req.Email, req.Notes,
+	).Scan(&cust.ID, &cust.Name, &cust.Phone, &cust.Email, &cust.Notes, &cust.CreatedAt)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка создания покупателя",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    cust,
+		Message: "Покупатель успешно создан",
+	})
+}
+
+// UpdateCustomer обновляет данные покупателя
+func (h *CustomersHandler) UpdateCustomer(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID покупателя",
+		})
+		return
+	}
+
+	var req customerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	var cust models.Customer
+	err = h.DB.QueryRow(
+		`UPDATE customers SET name = $1, phone = $2, email = $3, notes = $4 WHERE id = $5
+         RETURNING id, name, phone, email, notes, created_at`,
+		req.Name, req.Phone, req.Email, req.Notes, id,
+	).Scan(&cust.ID, &cust.Name, &cust.Phone, &cust.Email, &cust.Notes, &cust.CreatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Покупатель не найден",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка обновления покупателя",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    cust,
+		Message: "Покупатель успешно обновлен",
+	})
+}
+
+// DeleteCustomer удаляет покупателя, его продажи остаются без привязки
+func (h *CustomersHandler) DeleteCustomer(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID покупателя",
+		})
+		return
+	}
+
+	result, err := h.DB.Exec("DELETE FROM customers WHERE id = $1", id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка удаления покупателя",
+		})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error:   "Покупатель не найден",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Покупатель успешно удален",
+	})
+}
+
+// GetCustomerSales возвращает историю покупок клиента
+func (h *CustomersHandler) GetCustomerSales(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID покупателя",
+		})
+		return
+	}
+
+	if !h.customerExists(c, id) {
+		return
+	}
+
+	rows, err := h.DB.Query(`
+        SELECT `+saleColumns+`
+        FROM sales
+        WHERE customer_id = $1
+        ORDER BY sale_date DESC
+    `, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения истории покупок",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var sales []models.Sale
+	for rows.Next() {
+		var sale models.Sale
+		if err := scanSale(rows, &sale); err != nil {
+			continue
+		}
+		sales = append(sales, sale)
+	}
+
+	if err := loadSalePayments(h.DB, sales); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения оплат продаж",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    sales,
+	})
+}
+
+// GetCustomerStats возвращает пожизненную ценность клиента (LTV) и сводку покупок
+func (h *CustomersHandler) GetCustomerStats(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID покупателя",
+		})
+		return
+	}
+
+	if !h.customerExists(c, id) {
+		return
+	}
+
+	stats := models.CustomerStats{CustomerID: id}
+	err = h.DB.QueryRow(`
+        SELECT COUNT(*), COALESCE(SUM(quantity), 0), COALESCE(SUM(amount), 0),
+               MIN(sale_date), MAX(sale_date)
+        FROM sales
+        WHERE customer_id = $1
+    `, id).Scan(&stats.PurchasesCount, &stats.ItemsCount, &stats.LifetimeValue,
+		&stats.FirstPurchase, &stats.LastPurchase)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка расчета статистики покупателя",
+		})
+		return
+	}
+
+	if stats.PurchasesCount > 0 {
+		stats.AverageTicket = stats.LifetimeValue / float64(stats.PurchasesCount)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    stats,
+	})
+}
+
+// customerExists проверяет наличие покупателя и при его отсутствии отвечает 404
+func (h *CustomersHandler) customerExists(c *gin.Context, id int) bool {
+	var exists bool
+	err := h.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM customers WHERE id = $1)", id).Scan(&exists)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения покупателя",
+		})
+		return false
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error:   "Покупатель не найден",
+		})
+		return false
+	}
+	return true
+}
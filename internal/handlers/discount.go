@@ -0,0 +1,87 @@
+// handlers/discount.go
+package handlers
+
+import (
+	"math"
+	"store_app/internal/models"
+	"time"
+)
+
+// discountRequest - ручная скидка, указанная кассиром при продаже
+type discountRequest struct {
+	Type  string  `json:"type" binding:"required,oneof=percent fixed"`
+	Value float64 `json:"value" binding:"required,gt=0"`
+}
+
+// amount возвращает размер ручной скидки для указанной суммы
+func (d discountRequest) amount(subtotal float64) float64 {
+	if d.Type == "percent" {
+		return round2(subtotal * math.Min(d.Value, 100) / 100)
+	}
+	return round2(math.Min(d.Value, subtotal))
+}
+
+// promotionDiscount рассчитывает скидку по промоакции для позиции
+func promotionDiscount(p models.Promotion, price float64, quantity int) float64 {
+	subtotal := price * float64(quantity)
+
+	var discount float64
+	switch p.Kind {
+	case "percent":
+		discount = subtotal * math.Min(p.Value, 100) / 100
+	case "fixed":
+		discount = p.Value * float64(quantity)
+	case "buy_x_get_y":
+		if p.BuyQuantity == nil || p.FreeQuantity == nil {
+			return 0
+		}
+		set := *p.BuyQuantity + *p.FreeQuantity
+		if set <= 0 {
+			return 0
+		}
+		freeUnits := quantity / set * *p.FreeQuantity
+		discount = float64(freeUnits) * price
+	}
+
+	return round2(math.Min(discount, subtotal))
+}
+
+// bestPromotion выбирает действующую промоакцию с максимальной скидкой для товара
+func bestPromotion(q queryer, warehouseID int, price float64, quantity int, at time.Time) (*models.Promotion, float64, error) {
+	rows, err := q.Query(`
+        WITH RECURSIVE product_categories AS (
+            SELECT c.id, c.parent_id
+            FROM categories c JOIN warehouses w ON w.category_id = c.id
+            WHERE w.id = $1
+            UNION ALL
+            SELECT c.id, c.parent_id
+            FROM categories c JOIN product_categories pc ON c.id = pc.parent_id
+        )
+        SELECT `+promotionColumns+`
+        FROM promotions
+        WHERE is_active
+          AND starts_at <= $2
+          AND (ends_at IS NULL OR ends_at > $2)
+          AND (warehouse_id IS NULL OR warehouse_id = $1)
+          AND (category_id IS NULL OR category_id IN (SELECT id FROM product_categories))
+    `, warehouseID, at)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var best *models.Promotion
+	var bestDiscount float64
+	for rows.Next() {
+		var p models.Promotion
+		if err := scanPromotion(rows, &p); err != nil {
+			return nil, 0, err
+		}
+		if d := promotionDiscount(p, price, quantity); d > bestDiscount {
+			promo := p
+			best, bestDiscount = &promo, d
+		}
+	}
+
+	return best, bestDiscount, rows.Err()
+}
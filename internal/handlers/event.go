@@ -0,0 +1,62 @@
+// handlers/events.go
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"store_app/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EventsHandler struct {
+	DB *sql.DB
+}
+
+func NewEventsHandler(db *sql.DB) *EventsHandler {
+	return &EventsHandler{DB: db}
+}
+
+// GetEvents возвращает последние события журнала, с параметром type - только события этого типа
+func (h *EventsHandler) GetEvents(c *gin.Context) {
+	limit, ok := queryInt(c, "limit")
+	if !ok {
+		return
+	}
+	if limit == nil || *limit < 1 || *limit > 500 {
+		l := 100
+		limit = &l
+	}
+
+	rows, err := h.DB.Query(`
+        SELECT id, type, message, COALESCE(payload, 'null'::jsonb), created_at
+        FROM events
+        WHERE $1 = '' OR type = $1
+        ORDER BY created_at DESC, id DESC
+        LIMIT $2
+    `, c.Query("type"), *limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения событий",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var events []models.Event
+	for rows.Next() {
+		var e models.Event
+		var payload []byte
+		if err := rows.Scan(&e.ID, &e.Type, &e.Message, &payload, &e.CreatedAt); err != nil {
+			continue
+		}
+		e.Payload = payload
+		events = append(events, e)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    events,
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"store_app/internal/models"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,11 +19,116 @@ func NewExpenseItemsHandler(db *sql.DB) *ExpenseItemsHandler {
 	return &ExpenseItemsHandler{DB: db}
 }
 
-// GetExpenseItems возвращает все статьи расходов
+type expenseItemRequest struct {
+	Name              string   `json:"name" binding:"required"`
+	ParentID          *int     `json:"parent_id"`
+	ApprovalThreshold *float64 `json:"approval_threshold" binding:"omitempty,min=0"`
+	AccountCode       *string  `json:"account_code"`
+}
+
+const expenseItemColumns = `id, name, parent_id, approval_threshold,
+        (SELECT a.code FROM accounts a WHERE a.id = expense_items.account_id)`
+
+func scanExpenseItem(row interface{ Scan(...interface{}) error }, item *models.ExpenseItem) error {
+	return row.Scan(&item.ID, &item.Name, &item.ParentID, &item.ApprovalThreshold, &item.AccountCode)
+}
+
+// expenseItemFilter возвращает SQL-условие "колонка входит в статью с номером параметра $param
+// или в любую из ее подстатей". Если параметр NULL, условие выполняется всегда.
+func expenseItemFilter(column string, param int) string {
+	return subtreeFilter("expense_items", column, param)
+}
+
+// expenseItemAncestors возвращает статью и всех ее родителей
+func expenseItemAncestors(q queryer, id int) (map[int]bool, error) {
+	rows, err := q.Query(`
+        WITH RECURSIVE ancestors AS (
+            SELECT id, parent_id FROM expense_items WHERE id = $1
+            UNION ALL
+            SELECT e.id, e.parent_id FROM expense_items e JOIN ancestors a ON e.id = a.parent_id
+        )
+        SELECT id FROM ancestors
+    `, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[int]bool)
+	for rows.Next() {
+		var ancestor int
+		if err := rows.Scan(&ancestor); err != nil {
+			return nil, err
+		}
+		ids[ancestor] = true
+	}
+	return ids, rows.Err()
+}
+
+// loadExpenseTotals возвращает дерево статей расходов с суммами утвержденных расходов за период
+// (границы включаются): Own - расходы на саму статью, Total - вместе со всеми подстатьями.
+// Второй результат - все статьи по ID, включая статьи без расходов.
+func loadExpenseTotals(q queryer, startDate, endDate time.Time) ([]*models.ExpenseReportItem, map[int]*models.ExpenseReportItem, error) {
+	rows, err := q.Query(`
+        SELECT e.id, e.name, e.parent_id, COALESCE(SUM(c.amount), 0)
+        FROM expense_items e
+        LEFT JOIN charges c ON c.expense_item_id = e.id AND c.status = 'approved'
+            AND c.charge_date BETWEEN $1 AND $2
+        GROUP BY e.id, e.name, e.parent_id
+        ORDER BY e.name
+    `, startDate, endDate)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var items []*models.ExpenseReportItem
+	byID := make(map[int]*models.ExpenseReportItem)
+	for rows.Next() {
+		var item models.ExpenseReportItem
+		if err := rows.Scan(&item.ExpenseItemID, &item.Name, &item.ParentID, &item.Own); err != nil {
+			return nil, nil, err
+		}
+		items = append(items, &item)
+		byID[item.ExpenseItemID] = &item
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var roots []*models.ExpenseReportItem
+	for _, item := range items {
+		if item.ParentID != nil {
+			if parent, ok := byID[*item.ParentID]; ok {
+				parent.Children = append(parent.Children, item)
+				continue
+			}
+		}
+		roots = append(roots, item)
+	}
+
+	for _, root := range roots {
+		rollUpExpenses(root)
+	}
+	return roots, byID, nil
+}
+
+// rollUpExpenses считает итог статьи вместе с подстатьями на каждом уровне
+func rollUpExpenses(item *models.ExpenseReportItem) float64 {
+	total := item.Own
+	for _, child := range item.Children {
+		total += rollUpExpenses(child)
+	}
+	item.Own = round2(item.Own)
+	item.Total = round2(total)
+	return total
+}
+
+// GetExpenseItems возвращает статьи расходов списком, с параметром tree=true - деревом
 func (h *ExpenseItemsHandler) GetExpenseItems(c *gin.Context) {
 	rows, err := h.DB.Query(`
-        SELECT id, name 
-        FROM expense_items 
+        SELECT ` + expenseItemColumns + `
+        FROM expense_items
         ORDER BY id
     `)
 	if err != nil {
@@ -34,13 +140,32 @@ func (h *ExpenseItemsHandler) GetExpenseItems(c *gin.Context) {
 	}
 	defer rows.Close()
 
-	var expenseItems []models.ExpenseItem
+	var expenseItems []*models.ExpenseItem
 	for rows.Next() {
 		var item models.ExpenseItem
-		if err := rows.Scan(&item.ID, &item.Name); err != nil {
+		if err := scanExpenseItem(rows, &item); err != nil {
 			continue
 		}
-		expenseItems = append(expenseItems, item)
+		expenseItems = append(expenseItems, &item)
+	}
+
+	if c.Query("tree") == "true" {
+		byID := make(map[int]*models.ExpenseItem, len(expenseItems))
+		for _, item := range expenseItems {
+			byID[item.ID] = item
+		}
+
+		var roots []*models.ExpenseItem
+		for _, item := range expenseItems {
+			if item.ParentID != nil {
+				if parent, ok := byID[*item.ParentID]; ok {
+					parent.Children = append(parent.Children, item)
+					continue
+				}
+			}
+			roots = append(roots, item)
+		}
+		expenseItems = roots
 	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
@@ -49,12 +174,46 @@ func (h *ExpenseItemsHandler) GetExpenseItems(c *gin.Context) {
 	})
 }
 
-// CreateExpenseItem создает новую статью расходов
-func (h *ExpenseItemsHandler) CreateExpenseItem(c *gin.Context) {
-	var req struct {
-		Name string `json:"name" binding:"required"`
+// GetExpenseItem возвращает статью расходов по ID
+func (h *ExpenseItemsHandler) GetExpenseItem(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID статьи расходов",
+		})
+		return
+	}
+
+	var item models.ExpenseItem
+	err = scanExpenseItem(h.DB.QueryRow("SELECT "+expenseItemColumns+" FROM expense_items WHERE id = $1", id), &item)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Статья расходов не найдена",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка получения статьи расходов",
+			})
+		}
+		return
 	}
 
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    item,
+	})
+}
+
+// CreateExpenseItem создает новую статью расходов, с parent_id - подстатью.
+// Расходы выше approval_threshold создаются в статусе ожидания утверждения,
+// account_code - счет затрат, на который проводятся расходы статьи.
+func (h *ExpenseItemsHandler) CreateExpenseItem(c *gin.Context) {
+	var req expenseItemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
@@ -63,31 +222,132 @@ func (h *ExpenseItemsHandler) CreateExpenseItem(c *gin.Context) {
 		return
 	}
 
-	var id int
-	err := h.DB.QueryRow(
-		"INSERT INTO expense_items (name) VALUES ($1) RETURNING id",
-		req.Name,
-	).Scan(&id)
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
+	accountID, apiErr := accountByCode(h.DB, req.AccountCode)
+	if apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
 			Success: false,
-			Error:   "Ошибка создания статьи расходов",
+			Error:   apiErr.Message,
 		})
 		return
 	}
 
+	var item models.ExpenseItem
+	err := scanExpenseItem(h.DB.QueryRow(`
+        INSERT INTO expense_items (name, parent_id, approval_threshold, account_id) VALUES ($1, $2, $3, $4)
+        RETURNING `+expenseItemColumns,
+		req.Name, req.ParentID, req.ApprovalThreshold, accountID,
+	), &item)
+
+	if err != nil {
+		h.expenseItemWriteError(c, err, "Ошибка создания статьи расходов")
+		return
+	}
+
 	c.JSON(http.StatusCreated, models.APIResponse{
 		Success: true,
-		Data: models.ExpenseItem{
-			ID:   id,
-			Name: req.Name,
-		},
+		Data:    item,
 		Message: "Статья расходов успешно создана",
 	})
 }
 
-// DeleteExpenseItem удаляет статью расходов
+// UpdateExpenseItem переименовывает статью расходов, переносит ее к другому родителю,
+// меняет порог утверждения или счет затрат. Уже созданные расходы и проводки не пересматриваются.
+func (h *ExpenseItemsHandler) UpdateExpenseItem(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID статьи расходов",
+		})
+		return
+	}
+
+	var req expenseItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	// Родитель не может быть самой статьей или ее подстатьей
+	if req.ParentID != nil {
+		ancestors, err := expenseItemAncestors(h.DB, *req.ParentID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка обновления статьи расходов",
+			})
+			return
+		}
+		if ancestors[id] {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "Статью нельзя вложить в саму себя или в ее подстатью",
+			})
+			return
+		}
+	}
+
+	accountID, apiErr := accountByCode(h.DB, req.AccountCode)
+	if apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	var item models.ExpenseItem
+	err = scanExpenseItem(h.DB.QueryRow(`
+        UPDATE expense_items SET name = $1, parent_id = $2, approval_threshold = $3, account_id = $4
+        WHERE id = $5
+        RETURNING `+expenseItemColumns,
+		req.Name, req.ParentID, req.ApprovalThreshold, accountID, id,
+	), &item)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Статья расходов не найдена",
+			})
+		} else {
+			h.expenseItemWriteError(c, err, "Ошибка обновления статьи расходов")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    item,
+		Message: "Статья расходов успешно обновлена",
+	})
+}
+
+func (h *ExpenseItemsHandler) expenseItemWriteError(c *gin.Context, err error, message string) {
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Error:   "Статья расходов с таким названием уже есть у этого родителя",
+		})
+		return
+	}
+	if isForeignKeyViolation(err) {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error:   "Родительская статья расходов не найдена",
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, models.APIResponse{
+		Success: false,
+		Error:   message,
+	})
+}
+
+// DeleteExpenseItem удаляет статью расходов без подстатей
 func (h *ExpenseItemsHandler) DeleteExpenseItem(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -98,6 +358,23 @@ func (h *ExpenseItemsHandler) DeleteExpenseItem(c *gin.Context) {
 		return
 	}
 
+	var hasChildren bool
+	err = h.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM expense_items WHERE parent_id = $1)", id).Scan(&hasChildren)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка удаления статьи расходов",
+		})
+		return
+	}
+	if hasChildren {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Error:   "Нельзя удалить статью расходов с подстатьями",
+		})
+		return
+	}
+
 	result, err := h.DB.Exec("DELETE FROM expense_items WHERE id = $1", id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
@@ -0,0 +1,118 @@
+// handlers/helpers.go
+package handlers
+
+import (
+	"database/sql"
+	"math"
+	"net/http"
+	"store_app/internal/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// queryer - общий интерфейс *sql.DB и *sql.Tx
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// isAdmin проверяет роль текущего пользователя
+func isAdmin(c *gin.Context) bool {
+	return c.GetString("role") == "admin"
+}
+
+// round2 округляет денежную сумму до копеек
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// isUniqueViolation проверяет, что ошибка вызвана нарушением уникальности
+func isUniqueViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23505"
+}
+
+// isForeignKeyViolation проверяет, что ошибка вызвана ссылкой на несуществующую запись
+func isForeignKeyViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23503"
+}
+
+// raisedException возвращает текст исключения, выброшенного триггером (RAISE EXCEPTION)
+func raisedException(err error) (string, bool) {
+	pqErr, ok := err.(*pq.Error)
+	if !ok || pqErr.Code != "P0001" {
+		return "", false
+	}
+	return pqErr.Message, true
+}
+
+// checkViolation возвращает текст нарушения проверки данных (check_violation),
+// в том числе выброшенного триггером с этим кодом
+func checkViolation(err error) (string, bool) {
+	pqErr, ok := err.(*pq.Error)
+	if !ok || pqErr.Code != "23514" {
+		return "", false
+	}
+	return pqErr.Message, true
+}
+
+// queryInt разбирает необязательный целочисленный параметр запроса.
+// При ошибке сам отвечает клиенту и возвращает false.
+func queryInt(c *gin.Context, name string) (*int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверное значение параметра " + name,
+		})
+		return nil, false
+	}
+	return &v, true
+}
+
+// queryBool разбирает необязательный логический параметр запроса.
+// При ошибке сам отвечает клиенту и возвращает false.
+func queryBool(c *gin.Context, name string) (*bool, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверное значение параметра " + name,
+		})
+		return nil, false
+	}
+	return &v, true
+}
+
+// queryFloat разбирает необязательный дробный параметр запроса.
+// При ошибке сам отвечает клиенту и возвращает false.
+func queryFloat(c *gin.Context, name string) (*float64, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверное значение параметра " + name,
+		})
+		return nil, false
+	}
+	return &v, true
+}
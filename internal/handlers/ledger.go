@@ -0,0 +1,409 @@
+// handlers/ledger.go
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"store_app/internal/ledger"
+	"store_app/internal/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LedgerHandler struct {
+	DB *sql.DB
+}
+
+func NewLedgerHandler(db *sql.DB) *LedgerHandler {
+	return &LedgerHandler{DB: db}
+}
+
+type accountRequest struct {
+	Code string `json:"code" binding:"required,max=16"`
+	Name string `json:"name" binding:"required"`
+	Type string `json:"type" binding:"omitempty,oneof=active passive active_passive"`
+}
+
+const accountColumns = `id, code, name, type`
+
+func scanAccount(row interface{ Scan(...interface{}) error }, a *models.Account) error {
+	return row.Scan(&a.ID, &a.Code, &a.Name, &a.Type)
+}
+
+// accountByCode возвращает ID счета по коду, nil - если код не указан
+func accountByCode(q queryer, code *string) (*int, *apiError) {
+	if code == nil {
+		return nil, nil
+	}
+
+	var id int
+	err := q.QueryRow("SELECT id FROM accounts WHERE code = $1", *code).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, &apiError{http.StatusNotFound, "Счет " + *code + " не найден в плане счетов"}
+	}
+	if err != nil {
+		return nil, &apiError{http.StatusInternalServerError, "Ошибка проверки счета"}
+	}
+	return &id, nil
+}
+
+// GetAccounts возвращает план счетов
+func (h *LedgerHandler) GetAccounts(c *gin.Context) {
+	rows, err := h.DB.Query("SELECT " + accountColumns + " FROM accounts ORDER BY code")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения плана счетов",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var accounts []models.Account
+	for rows.Next() {
+		var a models.Account
+		if err := scanAccount(rows, &a); err != nil {
+			continue
+		}
+		accounts = append(accounts, a)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    accounts,
+	})
+}
+
+// CreateAccount добавляет счет или субсчет в план счетов
+func (h *LedgerHandler) CreateAccount(c *gin.Context) {
+	var req accountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+	if req.Type == "" {
+		req.Type = "active_passive"
+	}
+
+	var a models.Account
+	err := scanAccount(h.DB.QueryRow(
+		"INSERT INTO accounts (code, name, type) VALUES ($1, $2, $3) RETURNING "+accountColumns,
+		req.Code, req.Name, req.Type,
+	), &a)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Error:   "Счет с таким кодом уже есть",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка создания счета",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    a,
+		Message: "Счет успешно создан",
+	})
+}
+
+// UpdateAccount изменяет название и тип счета. Код счета не меняется: по нему
+// проводки выгружаются в 1С, а часть счетов используется в проводках всегда.
+func (h *LedgerHandler) UpdateAccount(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID счета",
+		})
+		return
+	}
+
+	var req struct {
+		Name string `json:"name" binding:"required"`
+		Type string `json:"type" binding:"required,oneof=active passive active_passive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	var a models.Account
+	err = scanAccount(h.DB.QueryRow(
+		"UPDATE accounts SET name = $1, type = $2 WHERE id = $3 RETURNING "+accountColumns,
+		req.Name, req.Type, id,
+	), &a)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Счет не найден",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка обновления счета",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    a,
+		Message: "Счет успешно обновлен",
+	})
+}
+
+// GetPaymentMethodAccounts возвращает счета учета денег по способам оплаты
+func (h *LedgerHandler) GetPaymentMethodAccounts(c *gin.Context) {
+	rows, err := h.DB.Query(`
+        SELECT m.method, a.code, a.name
+        FROM payment_method_accounts m
+        JOIN accounts a ON a.id = m.account_id
+        ORDER BY m.method
+    `)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения счетов способов оплаты",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var mappings []models.PaymentMethodAccount
+	for rows.Next() {
+		var m models.PaymentMethodAccount
+		if err := rows.Scan(&m.Method, &m.AccountCode, &m.AccountName); err != nil {
+			continue
+		}
+		mappings = append(mappings, m)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    mappings,
+	})
+}
+
+// SetPaymentMethodAccount назначает счет учета денег способу оплаты.
+// Действует для новых продаж, уже сделанные проводки не меняются.
+func (h *LedgerHandler) SetPaymentMethodAccount(c *gin.Context) {
+	method := c.Param("method")
+	switch method {
+	case "cash", "card", "transfer", "gift_card":
+	default:
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неизвестный способ оплаты",
+		})
+		return
+	}
+
+	var req struct {
+		AccountCode string `json:"account_code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	accountID, apiErr := accountByCode(h.DB, &req.AccountCode)
+	if apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	_, err := h.DB.Exec(`
+        INSERT INTO payment_method_accounts (method, account_id) VALUES ($1, $2)
+        ON CONFLICT (method) DO UPDATE SET account_id = EXCLUDED.account_id
+    `, method, *accountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка назначения счета",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Счет способа оплаты назначен",
+	})
+}
+
+// GetJournalEntries возвращает проводки за период (границы включаются). Параметр format:
+// json (по умолчанию), csv или 1c - выгрузка файлом для загрузки в 1С.
+func (h *LedgerHandler) GetJournalEntries(c *gin.Context) {
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" && format != "1c" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат выгрузки, допустимы json, csv и 1c",
+		})
+		return
+	}
+
+	rows, err := h.DB.Query(`
+        SELECT j.id, j.entry_date, d.code, cr.code, j.amount, j.source_type, j.source_id, j.description
+        FROM journal_entries j
+        JOIN accounts d ON d.id = j.debit_account_id
+        JOIN accounts cr ON cr.id = j.credit_account_id
+        WHERE j.entry_date BETWEEN $1 AND $2
+        ORDER BY j.entry_date, j.id
+    `, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения проводок",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var entries []models.JournalEntry
+	for rows.Next() {
+		var e models.JournalEntry
+		if err := rows.Scan(&e.ID, &e.EntryDate, &e.DebitAccount, &e.CreditAccount, &e.Amount,
+			&e.SourceType, &e.SourceID, &e.Description); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Data:    entries,
+		})
+		return
+	}
+
+	name := fmt.Sprintf("journal_%s_%s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="`+name+`.csv"`)
+		err = ledger.WriteCSV(c.Writer, entries)
+	} else {
+		c.Header("Content-Type", "text/csv; charset=windows-1251")
+		c.Header("Content-Disposition", `attachment; filename="`+name+`_1c.csv"`)
+		err = ledger.Write1C(c.Writer, entries)
+	}
+	if err != nil {
+		c.Error(err)
+	}
+}
+
+// GetTrialBalance возвращает оборотно-сальдовую ведомость за период: сальдо на начало,
+// обороты по дебету и кредиту и сальдо на конец по каждому счету с движением или остатком.
+// Итоги по дебету и кредиту совпадают, так как каждая проводка сбалансирована.
+func (h *LedgerHandler) GetTrialBalance(c *gin.Context) {
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	rows, err := h.DB.Query(`
+        SELECT a.code, a.name,
+               COALESCE(SUM(j.amount) FILTER (WHERE j.debit_account_id = a.id AND j.entry_date < $1), 0),
+               COALESCE(SUM(j.amount) FILTER (WHERE j.credit_account_id = a.id AND j.entry_date < $1), 0),
+               COALESCE(SUM(j.amount) FILTER (WHERE j.debit_account_id = a.id AND j.entry_date >= $1), 0),
+               COALESCE(SUM(j.amount) FILTER (WHERE j.credit_account_id = a.id AND j.entry_date >= $1), 0)
+        FROM accounts a
+        JOIN journal_entries j ON (j.debit_account_id = a.id OR j.credit_account_id = a.id)
+            AND j.entry_date <= $2
+        GROUP BY a.id, a.code, a.name
+        ORDER BY a.code
+    `, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения оборотно-сальдовой ведомости",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var accounts []models.TrialBalanceRow
+	var totals models.TrialBalanceRow
+	for rows.Next() {
+		var row models.TrialBalanceRow
+		var openingDebit, openingCredit float64
+		if err := rows.Scan(&row.AccountCode, &row.AccountName, &openingDebit, &openingCredit,
+			&row.DebitTurnover, &row.CreditTurnover); err != nil {
+			continue
+		}
+
+		// Сальдо показывается на той стороне, где оно есть: дебетовое или кредитовое
+		opening := round2(openingDebit - openingCredit)
+		closing := round2(opening + row.DebitTurnover - row.CreditTurnover)
+		row.OpeningDebit, row.OpeningCredit = splitBalance(opening)
+		row.ClosingDebit, row.ClosingCredit = splitBalance(closing)
+		row.DebitTurnover = round2(row.DebitTurnover)
+		row.CreditTurnover = round2(row.CreditTurnover)
+
+		if row == (models.TrialBalanceRow{AccountCode: row.AccountCode, AccountName: row.AccountName}) {
+			continue
+		}
+		accounts = append(accounts, row)
+
+		totals.OpeningDebit += row.OpeningDebit
+		totals.OpeningCredit += row.OpeningCredit
+		totals.DebitTurnover += row.DebitTurnover
+		totals.CreditTurnover += row.CreditTurnover
+		totals.ClosingDebit += row.ClosingDebit
+		totals.ClosingCredit += row.ClosingCredit
+	}
+
+	totals.OpeningDebit = round2(totals.OpeningDebit)
+	totals.OpeningCredit = round2(totals.OpeningCredit)
+	totals.DebitTurnover = round2(totals.DebitTurnover)
+	totals.CreditTurnover = round2(totals.CreditTurnover)
+	totals.ClosingDebit = round2(totals.ClosingDebit)
+	totals.ClosingCredit = round2(totals.ClosingCredit)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"start_date": startDate.Format("2006-01-02"),
+			"end_date":   endDate.Format("2006-01-02"),
+			"accounts":   accounts,
+			"totals":     totals,
+		},
+	})
+}
+
+// splitBalance раскладывает сальдо счета на дебетовое и кредитовое
+func splitBalance(balance float64) (float64, float64) {
+	if balance >= 0 {
+		return balance, 0
+	}
+	return 0, -balance
+}
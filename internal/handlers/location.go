@@ -0,0 +1,382 @@
+// handlers/locations.go
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"store_app/internal/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LocationsHandler struct {
+	DB *sql.DB
+}
+
+func NewLocationsHandler(db *sql.DB) *LocationsHandler {
+	return &LocationsHandler{DB: db}
+}
+
+type locationRequest struct {
+	Name      string `json:"name" binding:"required"`
+	Kind      string `json:"kind" binding:"omitempty,oneof=store backroom warehouse"`
+	IsDefault bool   `json:"is_default"`
+	IsActive  *bool  `json:"is_active"`
+}
+
+// GetLocations возвращает все локации
+func (h *LocationsHandler) GetLocations(c *gin.Context) {
+	rows, err := h.DB.Query(`
+        SELECT id, name, kind, is_default, is_active
+        FROM locations
+        ORDER BY id
+    `)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения локаций",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var locations []models.Location
+	for rows.Next() {
+		var l models.Location
+		if err := rows.Scan(&l.ID, &l.Name, &l.Kind, &l.IsDefault, &l.IsActive); err != nil {
+			continue
+		}
+		locations = append(locations, l)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    locations,
+	})
+}
+
+// GetLocation возвращает локацию по ID
+func (h *LocationsHandler) GetLocation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID локации",
+		})
+		return
+	}
+
+	var l models.Location
+	err = h.DB.QueryRow(
+		"SELECT id, name, kind, is_default, is_active FROM locations WHERE id = $1",
+		id,
+	).Scan(&l.ID, &l.Name, &l.Kind, &l.IsDefault, &l.IsActive)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Локация не найдена",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка получения локации",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    l,
+	})
+}
+
+// CreateLocation создает новую локацию
+func (h *LocationsHandler) CreateLocation(c *gin.Context) {
+	var req locationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	if req.Kind == "" {
+		req.Kind = "store"
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка начала транзакции",
+		})
+		return
+	}
+
+	// Новая основная локация снимает признак с предыдущей
+	if req.IsDefault {
+		if _, err := tx.Exec("UPDATE locations SET is_default = false WHERE is_default"); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка создания локации",
+			})
+			return
+		}
+	}
+
+	var l models.Location
+	err = tx.QueryRow(
+		`INSERT INTO locations (name, kind, is_default, is_active) VALUES ($1, $2, $3, COALESCE($4, true))
+         RETURNING id, name, kind, is_default, is_active`,
+		req.Name, req.Kind, req.IsDefault, req.IsActive,
+	).Scan(&l.ID, &l.Name, &l.Kind, &l.IsDefault, &l.IsActive)
+
+	if err != nil {
+		tx.Rollback()
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Error:   "Локация с таким названием уже существует",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка создания локации",
+			})
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка коммита транзакции",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    l,
+		Message: "Локация успешно создана",
+	})
+}
+
+// UpdateLocation обновляет локацию
+func (h *LocationsHandler) UpdateLocation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID локации",
+		})
+		return
+	}
+
+	var req locationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка начала транзакции",
+		})
+		return
+	}
+
+	if req.IsDefault {
+		if _, err := tx.Exec("UPDATE locations SET is_default = false WHERE is_default AND id <> $1", id); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка обновления локации",
+			})
+			return
+		}
+	}
+
+	// Снять признак основной можно только назначив основной другую локацию
+	var l models.Location
+	err = tx.QueryRow(
+		`UPDATE locations
+         SET name = $1, kind = COALESCE(NULLIF($2, ''), kind), is_default = is_default OR $3,
+             is_active = COALESCE($4, is_active)
+         WHERE id = $5
+         RETURNING id, name, kind, is_default, is_active`,
+		req.Name, req.Kind, req.IsDefault, req.IsActive, id,
+	).Scan(&l.ID, &l.Name, &l.Kind, &l.IsDefault, &l.IsActive)
+
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Локация не найдена",
+			})
+		} else if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Error:   "Локация с таким названием уже существует",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка обновления локации",
+			})
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка коммита транзакции",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    l,
+		Message: "Локация успешно обновлена",
+	})
+}
+
+// DeleteLocation удаляет пустую локацию, основную локацию удалить нельзя
+func (h *LocationsHandler) DeleteLocation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID локации",
+		})
+		return
+	}
+
+	var isDefault, hasStock bool
+	err = h.DB.QueryRow(`
+        SELECT l.is_default, EXISTS(SELECT 1 FROM stock_levels WHERE location_id = l.id AND quantity > 0)
+        FROM locations l
+        WHERE l.id = $1
+    `, id).Scan(&isDefault, &hasStock)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Локация не найдена",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка удаления локации",
+			})
+		}
+		return
+	}
+
+	if isDefault || hasStock {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Error:   "Нельзя удалить основную локацию или локацию с остатками",
+		})
+		return
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка начала транзакции",
+		})
+		return
+	}
+
+	if _, err := tx.Exec("DELETE FROM stock_levels WHERE location_id = $1", id); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка удаления локации",
+		})
+		return
+	}
+
+	// Локации с продажами или перемещениями защищены внешними ключами
+	if _, err := tx.Exec("DELETE FROM locations WHERE id = $1", id); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Error:   "Локация используется в продажах или перемещениях, ее можно только деактивировать",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка коммита транзакции",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Локация успешно удалена",
+	})
+}
+
+// GetLocationStock возвращает остатки всех товаров на локации
+func (h *LocationsHandler) GetLocationStock(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID локации",
+		})
+		return
+	}
+
+	rows, err := h.DB.Query(`
+        SELECT `+stockLevelColumns+`
+        FROM stock_levels sl
+        JOIN warehouses w ON w.id = sl.warehouse_id
+        JOIN locations l ON l.id = sl.location_id
+        WHERE sl.location_id = $1
+        ORDER BY w.name
+    `, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения остатков",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var stock []models.StockLevel
+	for rows.Next() {
+		var sl models.StockLevel
+		if err := scanStockLevel(rows, &sl); err != nil {
+			continue
+		}
+		stock = append(stock, sl)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    stock,
+	})
+}
@@ -0,0 +1,477 @@
+// handlers/lots.go
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"store_app/internal/ledger"
+	"store_app/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LotsHandler struct {
+	DB *sql.DB
+}
+
+func NewLotsHandler(db *sql.DB) *LotsHandler {
+	return &LotsHandler{DB: db}
+}
+
+// lotColumns - колонки партии для запросов к lots lt с warehouses w
+const lotColumns = `lt.id, lt.warehouse_id, w.name, lt.location_id, lt.lot_number, lt.expiry_date, lt.quantity,
+        lt.received_quantity, lt.received_at, COALESCE(lt.expiry_date < CURRENT_DATE, false)`
+
+// lotOrder - порядок FEFO: сначала партии с ближайшим сроком годности, партии без срока - последними
+const lotOrder = "lt.expiry_date NULLS LAST, lt.received_at, lt.id"
+
+func scanLot(row interface{ Scan(...interface{}) error }, l *models.Lot) error {
+	return row.Scan(&l.ID, &l.WarehouseID, &l.ProductName, &l.LocationID, &l.LotNumber, &l.ExpiryDate,
+		&l.Quantity, &l.ReceivedQuantity, &l.ReceivedAt, &l.Expired)
+}
+
+// lotAllocation - количество, списанное из партии
+type lotAllocation struct {
+	LotID    int
+	Quantity int
+}
+
+// errLotsExhausted - в партиях не хватает товара для списания
+var errLotsExhausted = errors.New("недостаточно товара в партиях")
+
+// allocateLots списывает quantity товара с партий на локации по FEFO и возвращает, из каких партий
+// списан товар. Остаток вне партий (принятый до начала учета партий) списывается первым.
+// Просроченные партии пропускаются, если includeExpired не задан. Для товара без партий
+// возвращает nil. Строка остатка должна быть заблокирована вызывающим кодом.
+func allocateLots(q queryer, warehouseID, locationID, quantity int, includeExpired bool) ([]lotAllocation, error) {
+	rows, err := q.Query(`
+        SELECT lt.id, lt.quantity, COALESCE(lt.expiry_date < CURRENT_DATE, false)
+        FROM lots lt
+        WHERE lt.warehouse_id = $1 AND lt.location_id = $2 AND lt.quantity > 0
+        ORDER BY `+lotOrder+`
+        FOR UPDATE
+    `, warehouseID, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	type lot struct {
+		id, quantity int
+		expired      bool
+	}
+	var lots []lot
+	var inLots int
+	for rows.Next() {
+		var l lot
+		if err := rows.Scan(&l.id, &l.quantity, &l.expired); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		inLots += l.quantity
+		lots = append(lots, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(lots) == 0 {
+		return nil, nil
+	}
+
+	onHand, err := lockStock(q, warehouseID, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := quantity
+	if untracked := onHand - inLots; untracked > 0 {
+		remaining -= min(untracked, remaining)
+	}
+
+	var allocations []lotAllocation
+	for _, l := range lots {
+		if remaining == 0 {
+			break
+		}
+		if l.expired && !includeExpired {
+			continue
+		}
+
+		n := min(l.quantity, remaining)
+		if _, err := q.Exec("UPDATE lots SET quantity = quantity - $1 WHERE id = $2", n, l.id); err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, lotAllocation{LotID: l.id, Quantity: n})
+		remaining -= n
+	}
+
+	if remaining > 0 {
+		return nil, errLotsExhausted
+	}
+	return allocations, nil
+}
+
+// moveLots приходует списанные из партий количества на локацию назначения
+// под теми же номерами и сроками годности
+func moveLots(q queryer, allocations []lotAllocation, toLocationID int) error {
+	for _, a := range allocations {
+		_, err := q.Exec(`
+            INSERT INTO lots (warehouse_id, location_id, lot_number, expiry_date, quantity, received_quantity, received_at)
+            SELECT warehouse_id, $2, lot_number, expiry_date, $3, $3, received_at FROM lots WHERE id = $1
+            ON CONFLICT (warehouse_id, location_id, lot_number) DO UPDATE
+            SET quantity = lots.quantity + EXCLUDED.quantity,
+                received_quantity = lots.received_quantity + EXCLUDED.received_quantity
+        `, a.LotID, toLocationID, a.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadSaleLots загружает партии, из которых списан товар продажи
+func loadSaleLots(q queryer, s *models.Sale) error {
+	rows, err := q.Query(`
+        SELECT sl.lot_id, lt.lot_number, lt.expiry_date, sl.quantity
+        FROM sale_lots sl
+        JOIN lots lt ON lt.id = sl.lot_id
+        WHERE sl.sale_id = $1
+        ORDER BY `+lotOrder,
+		s.ID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l models.SaleLot
+		if err := rows.Scan(&l.LotID, &l.LotNumber, &l.ExpiryDate, &l.Quantity); err != nil {
+			return err
+		}
+		s.Lots = append(s.Lots, l)
+	}
+	return rows.Err()
+}
+
+// GetWarehouseLots возвращает партии товара с остатком в порядке FEFO, поддерживает фильтр location_id
+func (h *LotsHandler) GetWarehouseLots(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID товара",
+		})
+		return
+	}
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
+
+	rows, err := h.DB.Query(`
+        SELECT `+lotColumns+`
+        FROM lots lt
+        JOIN warehouses w ON w.id = lt.warehouse_id
+        WHERE lt.warehouse_id = $1 AND lt.quantity > 0 AND ($2::int IS NULL OR lt.location_id = $2)
+        ORDER BY `+lotOrder,
+		id, locationID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения партий",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var lots []models.Lot
+	for rows.Next() {
+		var l models.Lot
+		if err := scanLot(rows, &l); err != nil {
+			continue
+		}
+		lots = append(lots, l)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    lots,
+	})
+}
+
+// ReceiveLot приходует партию товара на локацию. Повторный приход с тем же номером
+// добавляется к партии, если срок годности совпадает.
+func (h *LotsHandler) ReceiveLot(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID товара",
+		})
+		return
+	}
+
+	var req struct {
+		LotNumber  string `json:"lot_number" binding:"required,max=50"`
+		ExpiryDate string `json:"expiry_date"`
+		Quantity   int    `json:"quantity" binding:"required,min=1"`
+		LocationID *int   `json:"location_id"`
+
+		// Поставщик и закупочная цена обновляют последнюю закупочную цену товара у поставщика
+		SupplierID    *int     `json:"supplier_id"`
+		PurchasePrice *float64 `json:"purchase_price" binding:"omitempty,min=0"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	var expiryDate *time.Time
+	if req.ExpiryDate != "" {
+		d, err := time.Parse("2006-01-02", req.ExpiryDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "Неверный формат срока годности",
+			})
+			return
+		}
+		expiryDate = &d
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка начала транзакции",
+		})
+		return
+	}
+
+	if apiErr := ensurePeriodOpen(tx, time.Now()); apiErr != nil {
+		tx.Rollback()
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	locationID, apiErr := resolveLocation(tx, req.LocationID)
+	if apiErr != nil {
+		tx.Rollback()
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	if apiErr := checkSupplier(tx, req.SupplierID); apiErr != nil {
+		tx.Rollback()
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	// Остаток блокируем раньше партии, как продажа и перемещение, иначе возможна взаимоблокировка
+	_, err = lockStock(tx, id, locationID)
+
+	var lotID int
+	if err == nil {
+		err = tx.QueryRow(`
+        INSERT INTO lots (warehouse_id, location_id, lot_number, expiry_date, quantity, received_quantity)
+        VALUES ($1, $2, $3, $4, $5, $5)
+        ON CONFLICT (warehouse_id, location_id, lot_number) DO UPDATE
+        SET quantity = lots.quantity + EXCLUDED.quantity,
+            received_quantity = lots.received_quantity + EXCLUDED.received_quantity
+        WHERE lots.expiry_date IS NOT DISTINCT FROM EXCLUDED.expiry_date
+        RETURNING id
+    `, id, locationID, req.LotNumber, expiryDate, req.Quantity).Scan(&lotID)
+	}
+	if err == nil {
+		err = adjustStock(tx, id, locationID, req.Quantity)
+	}
+	if err == nil && req.SupplierID != nil && req.PurchasePrice != nil {
+		_, err = tx.Exec(`
+            INSERT INTO product_suppliers (warehouse_id, supplier_id, last_purchase_price, last_purchased_at)
+            VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+            ON CONFLICT (warehouse_id, supplier_id) DO UPDATE
+            SET last_purchase_price = EXCLUDED.last_purchase_price, last_purchased_at = EXCLUDED.last_purchased_at
+        `, id, *req.SupplierID, *req.PurchasePrice)
+	}
+	if err == nil && req.PurchasePrice != nil {
+		err = ledger.PostPurchase(tx, lotID, *req.PurchasePrice*float64(req.Quantity), time.Now(),
+			fmt.Sprintf("Поступление партии %s товара №%d", req.LotNumber, id))
+	}
+
+	if err != nil {
+		tx.Rollback()
+		switch {
+		case err == sql.ErrNoRows:
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Error:   "Партия с таким номером уже есть на локации с другим сроком годности",
+			})
+		case isForeignKeyViolation(err):
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Товар не найден",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка прихода партии",
+			})
+		}
+		return
+	}
+
+	var l models.Lot
+	err = scanLot(tx.QueryRow(`
+        SELECT `+lotColumns+`
+        FROM lots lt
+        JOIN warehouses w ON w.id = lt.warehouse_id
+        WHERE lt.id = $1
+    `, lotID), &l)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения партии",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка коммита транзакции",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    l,
+		Message: "Партия успешно оприходована",
+	})
+}
+
+// WriteOffLot списывает весь остаток партии (например, просроченной) с записью корректировки
+func (h *LotsHandler) WriteOffLot(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID партии",
+		})
+		return
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка начала транзакции",
+		})
+		return
+	}
+
+	if apiErr := ensurePeriodOpen(tx, time.Now()); apiErr != nil {
+		tx.Rollback()
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	// Товар и локация партии не меняются: по ним сначала блокируем остаток, затем партию -
+	// в том же порядке, что продажа и перемещение, иначе возможна взаимоблокировка
+	var warehouseID, locationID, quantity, onHand int
+	var unitValue float64
+	err = tx.QueryRow("SELECT warehouse_id, location_id FROM lots WHERE id = $1", id).Scan(&warehouseID, &locationID)
+	if err == nil {
+		onHand, err = lockStock(tx, warehouseID, locationID)
+	}
+	if err == nil {
+		err = tx.QueryRow(`
+            SELECT lt.quantity, w.amount
+            FROM lots lt
+            JOIN warehouses w ON w.id = lt.warehouse_id
+            WHERE lt.id = $1
+            FOR UPDATE OF lt
+        `, id).Scan(&quantity, &unitValue)
+	}
+
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Партия не найдена",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка получения партии",
+			})
+		}
+		return
+	}
+
+	if quantity == 0 {
+		tx.Rollback()
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Error:   "Партия уже израсходована",
+		})
+		return
+	}
+
+	// После инвентаризации в партии может числиться больше, чем есть на локации
+	_, err = tx.Exec("UPDATE lots SET quantity = 0 WHERE id = $1", id)
+	if n := min(quantity, onHand); err == nil && n > 0 {
+		_, err = tx.Exec(`
+            INSERT INTO stock_adjustments (warehouse_id, location_id, quantity, unit_value, reason, created_by)
+            VALUES ($1, $2, $3, $4, 'write_off', $5)
+        `, warehouseID, locationID, -n, unitValue, c.GetString("username"))
+		if err == nil {
+			err = adjustStock(tx, warehouseID, locationID, -n)
+		}
+	}
+
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка списания партии",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка коммита транзакции",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Партия списана",
+	})
+}
@@ -0,0 +1,340 @@
+// handlers/periods.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"store_app/internal/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PeriodsHandler struct {
+	DB *sql.DB
+}
+
+func NewPeriodsHandler(db *sql.DB) *PeriodsHandler {
+	return &PeriodsHandler{DB: db}
+}
+
+const periodColumns = `year, month, status, snapshot, closed_by, closed_at, reopened_by, reopened_at`
+
+func scanPeriod(row interface{ Scan(...interface{}) error }, p *models.AccountingPeriod) error {
+	var snapshot []byte
+	if err := row.Scan(&p.Year, &p.Month, &p.Status, &snapshot, &p.ClosedBy, &p.ClosedAt,
+		&p.ReopenedBy, &p.ReopenedAt); err != nil {
+		return err
+	}
+	if snapshot == nil {
+		return nil
+	}
+	p.Snapshot = &models.PeriodSnapshot{}
+	return json.Unmarshal(snapshot, p.Snapshot)
+}
+
+// lockPeriod берет блокировку учетного периода до конца транзакции: разделяемую для изменений
+// в периоде, исключительную для закрытия. Период не закроется, пока в нем проводится документ,
+// а документ, начатый во время закрытия, дождется его и будет отклонен.
+func lockPeriod(q queryer, year, month int, exclusive bool) error {
+	lock := "pg_advisory_xact_lock_shared"
+	if exclusive {
+		lock = "pg_advisory_xact_lock"
+	}
+	_, err := q.Exec("SELECT "+lock+"(hashtext('accounting_periods'), $1)", year*100+month)
+	return err
+}
+
+// ensurePeriodOpen проверяет, что дата документа попадает в открытый учетный период.
+// Вызывается в транзакции изменения, до ее конца период нельзя закрыть.
+func ensurePeriodOpen(q queryer, date time.Time) *apiError {
+	year, month := date.Year(), int(date.Month())
+	if err := lockPeriod(q, year, month, false); err != nil {
+		return &apiError{http.StatusInternalServerError, "Ошибка проверки учетного периода"}
+	}
+
+	var closed bool
+	err := q.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM accounting_periods WHERE year = $1 AND month = $2 AND status = 'closed')",
+		year, month,
+	).Scan(&closed)
+	if err != nil {
+		return &apiError{http.StatusInternalServerError, "Ошибка проверки учетного периода"}
+	}
+	if closed {
+		return &apiError{
+			http.StatusConflict,
+			fmt.Sprintf("Учетный период %02d.%d закрыт, изменения в нем запрещены", month, year),
+		}
+	}
+	return nil
+}
+
+// periodSnapshot считает итоги отчетов за месяц, начинающийся с startDate
+func periodSnapshot(q queryer, startDate time.Time) (models.PeriodSnapshot, error) {
+	endDate := startDate.AddDate(0, 1, 0).Add(-time.Nanosecond)
+
+	var s models.PeriodSnapshot
+	err := q.QueryRow(`
+        SELECT COUNT(*), COALESCE(SUM(amount), 0), COALESCE(SUM(COALESCE(subtotal, amount)), 0),
+               COALESCE(SUM(discount_amount), 0), COALESCE(SUM(tax_amount), 0)
+        FROM sales
+        WHERE sale_date BETWEEN $1 AND $2
+    `, startDate, endDate).Scan(&s.SalesCount, &s.Revenue, &s.GrossRevenue, &s.Discounts, &s.Tax)
+	if err != nil {
+		return s, err
+	}
+
+	err = q.QueryRow(`
+        SELECT COUNT(*), COALESCE(SUM(amount), 0)
+        FROM charges
+        WHERE charge_date BETWEEN $1 AND $2 AND status = 'approved'
+    `, startDate, endDate).Scan(&s.ChargesCount, &s.Expenses)
+	if err != nil {
+		return s, err
+	}
+
+	err = q.QueryRow(`
+        SELECT COALESCE(SUM(quantity * unit_value), 0)
+        FROM stock_adjustments
+        WHERE created_at BETWEEN $1 AND $2
+    `, startDate, endDate).Scan(&s.StockAdjustments)
+	if err != nil {
+		return s, err
+	}
+
+	s.Revenue = round2(s.Revenue)
+	s.GrossRevenue = round2(s.GrossRevenue)
+	s.Discounts = round2(s.Discounts)
+	s.Tax = round2(s.Tax)
+	s.Expenses = round2(s.Expenses)
+	s.Profit = round2(s.Revenue - s.Expenses)
+	s.StockAdjustments = round2(s.StockAdjustments)
+	return s, nil
+}
+
+// parsePeriod разбирает год и месяц из пути запроса
+func parsePeriod(c *gin.Context) (time.Time, bool) {
+	return parseMonthValues(c, c.Param("month"), c.Param("year"))
+}
+
+// GetPeriods возвращает закрывавшиеся учетные периоды, поддерживает фильтр year.
+// Месяцы, которые ни разу не закрывались, открыты и в список не входят.
+func (h *PeriodsHandler) GetPeriods(c *gin.Context) {
+	year, ok := queryInt(c, "year")
+	if !ok {
+		return
+	}
+
+	rows, err := h.DB.Query(`
+        SELECT `+periodColumns+`
+        FROM accounting_periods
+        WHERE ($1::int IS NULL OR year = $1)
+        ORDER BY year DESC, month DESC
+    `, year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения учетных периодов",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var periods []models.AccountingPeriod
+	for rows.Next() {
+		var p models.AccountingPeriod
+		if err := scanPeriod(rows, &p); err != nil {
+			continue
+		}
+		periods = append(periods, p)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    periods,
+	})
+}
+
+// GetPeriod возвращает состояние учетного периода
+func (h *PeriodsHandler) GetPeriod(c *gin.Context) {
+	startDate, ok := parsePeriod(c)
+	if !ok {
+		return
+	}
+
+	p := models.AccountingPeriod{Year: startDate.Year(), Month: int(startDate.Month()), Status: "open"}
+	err := scanPeriod(h.DB.QueryRow(
+		"SELECT "+periodColumns+" FROM accounting_periods WHERE year = $1 AND month = $2",
+		p.Year, p.Month,
+	), &p)
+
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения учетного периода",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    p,
+	})
+}
+
+// ClosePeriod закрывает завершившийся учетный период и сохраняет его итоги.
+// Расходы, ожидающие утверждения, нужно утвердить или отклонить до закрытия.
+func (h *PeriodsHandler) ClosePeriod(c *gin.Context) {
+	startDate, ok := parsePeriod(c)
+	if !ok {
+		return
+	}
+	year, month := startDate.Year(), int(startDate.Month())
+
+	if startDate.AddDate(0, 1, 0).After(time.Now()) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Нельзя закрыть период до его окончания",
+		})
+		return
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка начала транзакции",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	// Дожидаемся документов, которые сейчас проводятся в этом периоде
+	if err := lockPeriod(tx, year, month, true); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка закрытия периода",
+		})
+		return
+	}
+
+	var pending int
+	err = tx.QueryRow(`
+        SELECT COUNT(*) FROM charges
+        WHERE status = 'pending' AND charge_date >= $1 AND charge_date < $2
+    `, startDate, startDate.AddDate(0, 1, 0)).Scan(&pending)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка закрытия периода",
+		})
+		return
+	}
+	if pending > 0 {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("В периоде есть расходы, ожидающие утверждения: %d", pending),
+		})
+		return
+	}
+
+	snapshot, err := periodSnapshot(tx, startDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка расчета итогов периода",
+		})
+		return
+	}
+	snapshotJSON, err := json.Marshal(snapshot)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка расчета итогов периода",
+		})
+		return
+	}
+
+	var p models.AccountingPeriod
+	err = scanPeriod(tx.QueryRow(`
+        INSERT INTO accounting_periods (year, month, status, snapshot, closed_by, closed_at)
+        VALUES ($1, $2, 'closed', $3, $4, CURRENT_TIMESTAMP)
+        ON CONFLICT (year, month) DO UPDATE
+        SET status = 'closed', snapshot = EXCLUDED.snapshot,
+            closed_by = EXCLUDED.closed_by, closed_at = EXCLUDED.closed_at
+        WHERE accounting_periods.status = 'open'
+        RETURNING `+periodColumns,
+		year, month, snapshotJSON, c.GetString("username"),
+	), &p)
+
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Error:   "Период уже закрыт",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка закрытия периода",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка коммита транзакции",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    p,
+		Message: "Период закрыт",
+	})
+}
+
+// ReopenPeriod открывает закрытый период для исправлений. Итоги последнего закрытия
+// сохраняются до повторного закрытия.
+func (h *PeriodsHandler) ReopenPeriod(c *gin.Context) {
+	startDate, ok := parsePeriod(c)
+	if !ok {
+		return
+	}
+
+	var p models.AccountingPeriod
+	err := scanPeriod(h.DB.QueryRow(`
+        UPDATE accounting_periods
+        SET status = 'open', reopened_by = $1, reopened_at = CURRENT_TIMESTAMP
+        WHERE year = $2 AND month = $3 AND status = 'closed'
+        RETURNING `+periodColumns,
+		c.GetString("username"), startDate.Year(), int(startDate.Month()),
+	), &p)
+
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Error:   "Период не закрыт",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка открытия периода",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    p,
+		Message: "Период открыт",
+	})
+}
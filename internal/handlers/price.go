@@ -0,0 +1,227 @@
+// handlers/prices.go
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"store_app/internal/config"
+	"store_app/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PricesHandler struct {
+	DB    *sql.DB
+	Store config.StoreConfig
+}
+
+func NewPricesHandler(db *sql.DB) *PricesHandler {
+	return &PricesHandler{DB: db, Store: config.Load().Store}
+}
+
+const priceChangeColumns = `id, warehouse_id, amount, effective_at, effective_at > CURRENT_TIMESTAMP,
+        COALESCE(created_by, ''), created_at`
+
+func scanPriceChange(row interface{ Scan(...interface{}) error }, p *models.PriceChange) error {
+	return row.Scan(&p.ID, &p.WarehouseID, &p.Amount, &p.EffectiveAt, &p.Scheduled, &p.CreatedBy, &p.CreatedAt)
+}
+
+// recordPrice записывает в историю цену товара, действующую с текущего момента
+func recordPrice(q queryer, warehouseID int, amount float64, createdBy string) error {
+	_, err := q.Exec(
+		"INSERT INTO price_history (warehouse_id, amount, created_by) VALUES ($1, $2, $3)",
+		warehouseID, amount, createdBy,
+	)
+	return err
+}
+
+// GetPriceHistory возвращает историю цен товара вместе с запланированными изменениями, новые - первыми
+func (h *PricesHandler) GetPriceHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID товара",
+		})
+		return
+	}
+
+	rows, err := h.DB.Query(`
+        SELECT `+priceChangeColumns+`
+        FROM price_history
+        WHERE warehouse_id = $1
+        ORDER BY effective_at DESC, id DESC
+    `, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения истории цен",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var prices []models.PriceChange
+	for rows.Next() {
+		var p models.PriceChange
+		if err := scanPriceChange(rows, &p); err != nil {
+			continue
+		}
+		prices = append(prices, p)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    prices,
+	})
+}
+
+// SchedulePriceChange планирует изменение цены товара на будущий момент.
+// Немедленное изменение цены выполняется через PUT /warehouses/:id.
+func (h *PricesHandler) SchedulePriceChange(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID товара",
+		})
+		return
+	}
+
+	var req struct {
+		Amount      float64   `json:"amount" binding:"min=0"`
+		EffectiveAt time.Time `json:"effective_at" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	if !req.EffectiveAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Дата вступления цены в силу должна быть в будущем",
+		})
+		return
+	}
+
+	// effective_at - timestamp без пояса: смещение клиента при записи отбрасывается,
+	// поэтому переводим момент в пояс, в котором время хранится в БД (DATA_TIMEZONE)
+	dataLoc, err := time.LoadLocation(h.Store.DataTimeZone)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Неверный часовой пояс DATA_TIMEZONE",
+		})
+		return
+	}
+
+	var p models.PriceChange
+	err = scanPriceChange(h.DB.QueryRow(`
+        INSERT INTO price_history (warehouse_id, amount, effective_at, created_by)
+        VALUES ($1, $2, $3, $4)
+        RETURNING `+priceChangeColumns,
+		id, req.Amount, req.EffectiveAt.In(dataLoc), c.GetString("username"),
+	), &p)
+
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Товар не найден",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка планирования цены",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    p,
+		Message: "Изменение цены запланировано",
+	})
+}
+
+// CancelPriceChange отменяет запланированное изменение цены, вступившие в силу цены не удаляются
+func (h *PricesHandler) CancelPriceChange(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID товара",
+		})
+		return
+	}
+
+	priceID, err := strconv.Atoi(c.Param("price_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID изменения цены",
+		})
+		return
+	}
+
+	var scheduled bool
+	err = h.DB.QueryRow(
+		"SELECT effective_at > CURRENT_TIMESTAMP FROM price_history WHERE id = $1 AND warehouse_id = $2",
+		priceID, id,
+	).Scan(&scheduled)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Изменение цены не найдено",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка отмены изменения цены",
+			})
+		}
+		return
+	}
+
+	if !scheduled {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Error:   "Цена уже вступила в силу, историю цен изменить нельзя",
+		})
+		return
+	}
+
+	result, err := h.DB.Exec(
+		"DELETE FROM price_history WHERE id = $1 AND effective_at > CURRENT_TIMESTAMP",
+		priceID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка отмены изменения цены",
+		})
+		return
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Error:   "Цена уже вступила в силу, историю цен изменить нельзя",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Изменение цены отменено",
+	})
+}
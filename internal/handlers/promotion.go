@@ -0,0 +1,275 @@
+// handlers/promotions.go
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"store_app/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromotionsHandler struct {
+	DB *sql.DB
+}
+
+func NewPromotionsHandler(db *sql.DB) *PromotionsHandler {
+	return &PromotionsHandler{DB: db}
+}
+
+type promotionRequest struct {
+	Name         string     `json:"name" binding:"required"`
+	Kind         string     `json:"kind" binding:"required,oneof=percent fixed buy_x_get_y"`
+	Value        float64    `json:"value" binding:"min=0"`
+	BuyQuantity  *int       `json:"buy_quantity" binding:"omitempty,min=1"`
+	FreeQuantity *int       `json:"free_quantity" binding:"omitempty,min=1"`
+	WarehouseID  *int       `json:"warehouse_id"`
+	CategoryID   *int       `json:"category_id"`
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+	IsActive     *bool      `json:"is_active"`
+}
+
+// validate проверяет согласованность параметров акции и возвращает текст ошибки
+func (r *promotionRequest) validate() string {
+	switch r.Kind {
+	case "percent":
+		if r.Value <= 0 || r.Value > 100 {
+			return "Процент скидки должен быть от 0 до 100"
+		}
+	case "fixed":
+		if r.Value <= 0 {
+			return "Сумма скидки должна быть больше нуля"
+		}
+	case "buy_x_get_y":
+		if r.BuyQuantity == nil || r.FreeQuantity == nil {
+			return "Для акции buy_x_get_y нужно указать buy_quantity и free_quantity"
+		}
+	}
+	if r.StartsAt != nil && r.EndsAt != nil && !r.EndsAt.After(*r.StartsAt) {
+		return "Дата окончания акции должна быть позже даты начала"
+	}
+	return ""
+}
+
+const promotionColumns = "id, name, kind, value, buy_quantity, free_quantity, warehouse_id, category_id, starts_at, ends_at, is_active"
+
+func scanPromotion(row interface{ Scan(...interface{}) error }, p *models.Promotion) error {
+	return row.Scan(&p.ID, &p.Name, &p.Kind, &p.Value, &p.BuyQuantity, &p.FreeQuantity,
+		&p.WarehouseID, &p.CategoryID, &p.StartsAt, &p.EndsAt, &p.IsActive)
+}
+
+// GetPromotions возвращает все акции, с параметром current=true - только действующие сейчас
+func (h *PromotionsHandler) GetPromotions(c *gin.Context) {
+	current := c.Query("current") == "true"
+
+	rows, err := h.DB.Query(`
+        SELECT `+promotionColumns+`
+        FROM promotions
+        WHERE NOT $1 OR (is_active AND starts_at <= $2 AND (ends_at IS NULL OR ends_at > $2))
+        ORDER BY id
+    `, current, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения акций",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var promotions []models.Promotion
+	for rows.Next() {
+		var p models.Promotion
+		if err := scanPromotion(rows, &p); err != nil {
+			continue
+		}
+		promotions = append(promotions, p)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    promotions,
+	})
+}
+
+// GetPromotion возвращает акцию по ID
+func (h *PromotionsHandler) GetPromotion(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID акции",
+		})
+		return
+	}
+
+	var p models.Promotion
+	err = scanPromotion(h.DB.QueryRow("SELECT "+promotionColumns+" FROM promotions WHERE id = $1", id), &p)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Акция не найдена",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка получения акции",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    p,
+	})
+}
+
+// CreatePromotion создает новую акцию
+func (h *PromotionsHandler) CreatePromotion(c *gin.Context) {
+	var req promotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	if msg := req.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   msg,
+		})
+		return
+	}
+
+	startsAt := time.Now()
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+	isActive := req.IsActive == nil || *req.IsActive
+
+	var p models.Promotion
+	err := scanPromotion(h.DB.QueryRow(`
+        INSERT INTO promotions (name, kind, value, buy_quantity, free_quantity, warehouse_id, category_id, starts_at, ends_at, is_active)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        RETURNING `+promotionColumns,
+		req.Name, req.Kind, req.Value, req.BuyQuantity, req.FreeQuantity, req.WarehouseID, req.CategoryID,
+		startsAt, req.EndsAt, isActive,
+	), &p)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка создания акции",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    p,
+		Message: "Акция успешно создана",
+	})
+}
+
+// UpdatePromotion обновляет акцию
+func (h *PromotionsHandler) UpdatePromotion(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID акции",
+		})
+		return
+	}
+
+	var req promotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	if msg := req.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   msg,
+		})
+		return
+	}
+
+	var p models.Promotion
+	err = scanPromotion(h.DB.QueryRow(`
+        UPDATE promotions
+        SET name = $1, kind = $2, value = $3, buy_quantity = $4, free_quantity = $5, warehouse_id = $6,
+            category_id = $7, starts_at = COALESCE($8, starts_at), ends_at = $9, is_active = COALESCE($10, is_active)
+        WHERE id = $11
+        RETURNING `+promotionColumns,
+		req.Name, req.Kind, req.Value, req.BuyQuantity, req.FreeQuantity, req.WarehouseID, req.CategoryID,
+		req.StartsAt, req.EndsAt, req.IsActive, id,
+	), &p)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Акция не найдена",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка обновления акции",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    p,
+		Message: "Акция успешно обновлена",
+	})
+}
+
+// DeletePromotion удаляет акцию, продажи по ней сохраняют сумму скидки
+func (h *PromotionsHandler) DeletePromotion(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID акции",
+		})
+		return
+	}
+
+	result, err := h.DB.Exec("DELETE FROM promotions WHERE id = $1", id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка удаления акции",
+		})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error:   "Акция не найдена",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Акция успешно удалена",
+	})
+}
@@ -0,0 +1,420 @@
+// handlers/recurring_charges.go
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"store_app/internal/models"
+	"store_app/internal/recurrence"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecurringChargesHandler struct {
+	DB *sql.DB
+}
+
+func NewRecurringChargesHandler(db *sql.DB) *RecurringChargesHandler {
+	return &RecurringChargesHandler{DB: db}
+}
+
+// recurringChargeRequest - шаблон повторяющегося расхода. Даты - в формате YYYY-MM-DD,
+// без starts_on шаблон действует с сегодняшнего дня.
+type recurringChargeRequest struct {
+	Name          string  `json:"name" binding:"required"`
+	ExpenseItemID int     `json:"expense_item_id" binding:"required"`
+	SupplierID    *int    `json:"supplier_id"`
+	Amount        float64 `json:"amount" binding:"required,min=0"`
+	Frequency     string  `json:"frequency" binding:"required,oneof=monthly weekly"`
+	DayOfMonth    *int    `json:"day_of_month"`
+	DayOfWeek     *int    `json:"day_of_week"`
+	StartsOn      string  `json:"starts_on"`
+	EndsOn        string  `json:"ends_on"`
+	IsActive      *bool   `json:"is_active"`
+}
+
+// schedule разбирает даты и проверяет расписание шаблона
+func (r recurringChargeRequest) schedule() (recurrence.Schedule, *apiError) {
+	s := recurrence.Schedule{Frequency: r.Frequency, StartsOn: recurrence.Date(time.Now())}
+
+	// Для периодичности учитывается только ее день, второй сбрасывается
+	switch r.Frequency {
+	case recurrence.Monthly:
+		if r.DayOfMonth != nil {
+			s.DayOfMonth = *r.DayOfMonth
+		}
+	case recurrence.Weekly:
+		s.DayOfWeek = -1
+		if r.DayOfWeek != nil {
+			s.DayOfWeek = *r.DayOfWeek
+		}
+	}
+
+	if r.StartsOn != "" {
+		d, err := time.Parse("2006-01-02", r.StartsOn)
+		if err != nil {
+			return s, &apiError{http.StatusBadRequest, "Неверный формат даты начала"}
+		}
+		s.StartsOn = d
+	}
+	if r.EndsOn != "" {
+		d, err := time.Parse("2006-01-02", r.EndsOn)
+		if err != nil {
+			return s, &apiError{http.StatusBadRequest, "Неверный формат даты окончания"}
+		}
+		s.EndsOn = &d
+	}
+
+	if err := s.Validate(); err != nil {
+		return s, &apiError{http.StatusBadRequest, "Неверное расписание: " + err.Error()}
+	}
+	return s, nil
+}
+
+// dayColumns возвращает значения day_of_month и day_of_week для сохранения расписания
+func dayColumns(s recurrence.Schedule) (*int, *int) {
+	if s.Frequency == recurrence.Weekly {
+		return nil, &s.DayOfWeek
+	}
+	return &s.DayOfMonth, nil
+}
+
+const recurringChargeColumns = `id, name, expense_item_id, supplier_id, amount, frequency, day_of_month, day_of_week,
+        starts_on, ends_on, is_active, COALESCE(created_by, ''), created_at`
+
+// scanRecurringCharge читает шаблон и рассчитывает ближайшую дату создания расхода
+func scanRecurringCharge(row interface{ Scan(...interface{}) error }, r *models.RecurringCharge) error {
+	err := row.Scan(&r.ID, &r.Name, &r.ExpenseItemID, &r.SupplierID, &r.Amount, &r.Frequency, &r.DayOfMonth,
+		&r.DayOfWeek, &r.StartsOn, &r.EndsOn, &r.IsActive, &r.CreatedBy, &r.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if r.IsActive {
+		if next, ok := recurringSchedule(r).Next(time.Now()); ok {
+			r.NextOccurrence = &next
+		}
+	}
+	return nil
+}
+
+func recurringSchedule(r *models.RecurringCharge) recurrence.Schedule {
+	s := recurrence.Schedule{Frequency: r.Frequency, StartsOn: r.StartsOn, EndsOn: r.EndsOn}
+	if r.DayOfMonth != nil {
+		s.DayOfMonth = *r.DayOfMonth
+	}
+	if r.DayOfWeek != nil {
+		s.DayOfWeek = *r.DayOfWeek
+	}
+	return s
+}
+
+// GetRecurringCharges возвращает шаблоны повторяющихся расходов
+func (h *RecurringChargesHandler) GetRecurringCharges(c *gin.Context) {
+	rows, err := h.DB.Query("SELECT " + recurringChargeColumns + " FROM recurring_charges ORDER BY id")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения повторяющихся расходов",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var templates []models.RecurringCharge
+	for rows.Next() {
+		var r models.RecurringCharge
+		if err := scanRecurringCharge(rows, &r); err != nil {
+			continue
+		}
+		templates = append(templates, r)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    templates,
+	})
+}
+
+// GetRecurringCharge возвращает шаблон повторяющегося расхода по ID
+func (h *RecurringChargesHandler) GetRecurringCharge(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID повторяющегося расхода",
+		})
+		return
+	}
+
+	var r models.RecurringCharge
+	err = scanRecurringCharge(h.DB.QueryRow("SELECT "+recurringChargeColumns+" FROM recurring_charges WHERE id = $1", id), &r)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Повторяющийся расход не найден",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка получения повторяющегося расхода",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    r,
+	})
+}
+
+// CreateRecurringCharge создает шаблон повторяющегося расхода.
+// Расходы по нему создает фоновая задача начиная с даты создания шаблона.
+func (h *RecurringChargesHandler) CreateRecurringCharge(c *gin.Context) {
+	var req recurringChargeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	s, apiErr := req.schedule()
+	if apiErr == nil {
+		apiErr = checkSupplier(h.DB, req.SupplierID)
+	}
+	if apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	dayOfMonth, dayOfWeek := dayColumns(s)
+	var r models.RecurringCharge
+	err := scanRecurringCharge(h.DB.QueryRow(
+		`INSERT INTO recurring_charges (name, expense_item_id, supplier_id, amount, frequency, day_of_month, day_of_week,
+                                        starts_on, ends_on, is_active, created_by)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE($10, true), $11)
+         RETURNING `+recurringChargeColumns,
+		req.Name, req.ExpenseItemID, req.SupplierID, req.Amount, s.Frequency, dayOfMonth, dayOfWeek,
+		s.StartsOn, s.EndsOn, req.IsActive, c.GetString("username"),
+	), &r)
+
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Статья расходов не найдена",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка создания повторяющегося расхода",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    r,
+		Message: "Повторяющийся расход успешно создан",
+	})
+}
+
+// UpdateRecurringCharge обновляет шаблон. Уже созданные расходы не меняются.
+func (h *RecurringChargesHandler) UpdateRecurringCharge(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID повторяющегося расхода",
+		})
+		return
+	}
+
+	var req recurringChargeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	s, apiErr := req.schedule()
+	if apiErr == nil {
+		apiErr = checkSupplier(h.DB, req.SupplierID)
+	}
+	if apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	// Без starts_on сохраняется прежняя дата начала
+	var startsOn *time.Time
+	if req.StartsOn != "" {
+		startsOn = &s.StartsOn
+	}
+
+	dayOfMonth, dayOfWeek := dayColumns(s)
+	var r models.RecurringCharge
+	err = scanRecurringCharge(h.DB.QueryRow(
+		`UPDATE recurring_charges
+         SET name = $1, expense_item_id = $2, supplier_id = $3, amount = $4, frequency = $5,
+             day_of_month = $6, day_of_week = $7, starts_on = COALESCE($8, starts_on), ends_on = $9,
+             is_active = COALESCE($10, is_active)
+         WHERE id = $11
+         RETURNING `+recurringChargeColumns,
+		req.Name, req.ExpenseItemID, req.SupplierID, req.Amount, s.Frequency, dayOfMonth, dayOfWeek,
+		startsOn, s.EndsOn, req.IsActive, id,
+	), &r)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Повторяющийся расход не найден",
+			})
+		} else if isForeignKeyViolation(err) {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Статья расходов не найдена",
+			})
+		} else {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка обновления повторяющегося расхода, проверьте даты начала и окончания",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    r,
+		Message: "Повторяющийся расход успешно обновлен",
+	})
+}
+
+// DeleteRecurringCharge удаляет шаблон, созданные по нему расходы остаются
+func (h *RecurringChargesHandler) DeleteRecurringCharge(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID повторяющегося расхода",
+		})
+		return
+	}
+
+	result, err := h.DB.Exec("DELETE FROM recurring_charges WHERE id = $1", id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка удаления повторяющегося расхода",
+		})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error:   "Повторяющийся расход не найден",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Повторяющийся расход успешно удален",
+	})
+}
+
+// PreviewRecurringCharge возвращает ближайшие count (по умолчанию 5) дат создания расходов
+// начиная с сегодняшнего дня. Для уже созданных расходов указывается charge_id.
+func (h *RecurringChargesHandler) PreviewRecurringCharge(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID повторяющегося расхода",
+		})
+		return
+	}
+
+	count, ok := queryInt(c, "count")
+	if !ok {
+		return
+	}
+	if count == nil || *count < 1 || *count > 100 {
+		n := 5
+		count = &n
+	}
+
+	var r models.RecurringCharge
+	err = scanRecurringCharge(h.DB.QueryRow("SELECT "+recurringChargeColumns+" FROM recurring_charges WHERE id = $1", id), &r)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Повторяющийся расход не найден",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка получения повторяющегося расхода",
+			})
+		}
+		return
+	}
+
+	today := recurrence.Date(time.Now())
+	rows, err := h.DB.Query(
+		"SELECT occurrence_date, charge_id FROM recurring_charge_runs WHERE template_id = $1 AND occurrence_date >= $2",
+		id, today,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения созданных расходов",
+		})
+		return
+	}
+	defer rows.Close()
+
+	created := make(map[time.Time]*int)
+	for rows.Next() {
+		var date time.Time
+		var chargeID *int
+		if err := rows.Scan(&date, &chargeID); err != nil {
+			continue
+		}
+		created[recurrence.Date(date)] = chargeID
+	}
+
+	occurrences := []models.RecurringChargeOccurrence{}
+	for _, date := range recurringSchedule(&r).NextN(today, *count) {
+		occurrences = append(occurrences, models.RecurringChargeOccurrence{
+			Date:     date,
+			Amount:   r.Amount,
+			ChargeID: created[date],
+		})
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    occurrences,
+	})
+}
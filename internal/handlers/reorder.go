@@ -0,0 +1,148 @@
+// handlers/reorder.go
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"store_app/internal/models"
+	"store_app/internal/notify"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loadLowStock возвращает активные товары с остатком на уровне точки заказа или ниже
+// вместе с основным поставщиком. Без заданного объема заказа предлагается довести остаток
+// до двойной точки заказа.
+func loadLowStock(q queryer) ([]models.LowStockItem, error) {
+	rows, err := q.Query(`
+        SELECT w.id, w.name, COALESCE(w.sku, ''), w.quantity, w.reorder_point, w.reorder_quantity,
+               CASE WHEN w.reorder_quantity > 0 THEN w.reorder_quantity ELSE w.reorder_point * 2 - w.quantity END,
+               ps.supplier_id, COALESCE(s.name, ''), COALESCE(ps.supplier_sku, ''), ps.last_purchase_price
+        FROM warehouses w
+        LEFT JOIN product_suppliers ps ON ps.warehouse_id = w.id AND ps.is_primary
+        LEFT JOIN suppliers s ON s.id = ps.supplier_id
+        WHERE w.is_active AND w.reorder_point > 0 AND w.quantity <= w.reorder_point
+        ORDER BY w.quantity::float / w.reorder_point, w.name
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.LowStockItem
+	for rows.Next() {
+		var item models.LowStockItem
+		if err := rows.Scan(&item.WarehouseID, &item.Name, &item.SKU, &item.Quantity,
+			&item.ReorderPoint, &item.ReorderQuantity, &item.SuggestedQuantity,
+			&item.SupplierID, &item.SupplierName, &item.SupplierSKU, &item.LastPurchasePrice); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// lowStockEvent проверяет, опустился ли остаток товара до точки заказа после списания sold единиц.
+// Событие возвращается только в момент пересечения порога, чтобы не оповещать о каждой продаже.
+func lowStockEvent(q queryer, warehouseID, sold int) (*notify.Event, error) {
+	var item models.LowStockItem
+	err := q.QueryRow(`
+        SELECT id, name, COALESCE(sku, ''), quantity, reorder_point, reorder_quantity
+        FROM warehouses
+        WHERE id = $1
+    `, warehouseID).Scan(&item.WarehouseID, &item.Name, &item.SKU, &item.Quantity,
+		&item.ReorderPoint, &item.ReorderQuantity)
+	if err != nil {
+		return nil, err
+	}
+
+	if item.ReorderPoint == 0 || item.Quantity > item.ReorderPoint || item.Quantity+sold <= item.ReorderPoint {
+		return nil, nil
+	}
+
+	return &notify.Event{
+		Type:    notify.EventLowStock,
+		Message: fmt.Sprintf("Товар \"%s\" заканчивается: осталось %d (точка заказа %d)", item.Name, item.Quantity, item.ReorderPoint),
+		Payload: item,
+	}, nil
+}
+
+// GetLowStock возвращает товары, которые пора дозаказать
+func (h *WarehousesHandler) GetLowStock(c *gin.Context) {
+	items, err := loadLowStock(h.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения товаров с низким остатком",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    items,
+	})
+}
+
+// GetPurchaseList возвращает рекомендуемый список закупки, сгруппированный по поставщикам
+func (h *WarehousesHandler) GetPurchaseList(c *gin.Context) {
+	items, err := loadLowStock(h.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка формирования списка закупки",
+		})
+		return
+	}
+
+	terms, err := supplierPaymentTerms(h.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка формирования списка закупки",
+		})
+		return
+	}
+
+	// Товары группируются по основному поставщику, товары без поставщика - последней группой
+	var groups []models.PurchaseListGroup
+	index := make(map[int]int)
+	for _, item := range items {
+		key := 0
+		if item.SupplierID != nil {
+			key = *item.SupplierID
+		}
+
+		i, ok := index[key]
+		if !ok {
+			group := models.PurchaseListGroup{SupplierID: item.SupplierID, SupplierName: item.SupplierName}
+			if item.SupplierID == nil {
+				group.SupplierName = "Без поставщика"
+			} else {
+				group.PaymentTermsDays = terms[key]
+			}
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, group)
+		}
+
+		groups[i].Items = append(groups[i].Items, item)
+		groups[i].TotalQuantity += item.SuggestedQuantity
+		if item.LastPurchasePrice != nil {
+			groups[i].EstimatedCost = round2(groups[i].EstimatedCost + *item.LastPurchasePrice*float64(item.SuggestedQuantity))
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (groups[i].SupplierID == nil) != (groups[j].SupplierID == nil) {
+			return groups[j].SupplierID == nil
+		}
+		return groups[i].SupplierName < groups[j].SupplierName
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    groups,
+	})
+}
@@ -3,7 +3,9 @@ package handlers
 
 import (
 	"database/sql"
+	"math"
 	"net/http"
+	"store_app/internal/config"
 	"store_app/internal/models"
 	"strconv"
 	"time"
@@ -12,141 +14,1054 @@ import (
 )
 
 type ReportsHandler struct {
-	DB *sql.DB
+	DB    *sql.DB
+	Store config.StoreConfig
 }
 
 func NewReportsHandler(db *sql.DB) *ReportsHandler {
-	return &ReportsHandler{DB: db}
+	return &ReportsHandler{DB: db, Store: config.Load().Store}
 }
 
-// GetProfitReport возвращает отчет по прибыли за месяц
+// profitTotals считает доходы и утвержденные расходы за период.
+// Фильтр locationID ограничивает доходы продажами локации, расходы учитываются общие.
+func profitTotals(q queryer, startDate, endDate time.Time, locationID *int) (map[string]float64, *apiError) {
+	// Считаем доход от продаж за период: выручку до скидок, скидки и итог
+	var revenue, grossRevenue, discounts, tax float64
+	err := q.QueryRow(`
+		SELECT COALESCE(SUM(amount), 0), COALESCE(SUM(COALESCE(subtotal, amount)), 0),
+		       COALESCE(SUM(discount_amount), 0), COALESCE(SUM(tax_amount), 0)
+		FROM sales 
+		WHERE sale_date BETWEEN $1 AND $2 AND ($3::int IS NULL OR location_id = $3)
+	`, startDate, endDate, locationID).Scan(&revenue, &grossRevenue, &discounts, &tax)
+
+	if err != nil {
+		return nil, &apiError{http.StatusInternalServerError, "Ошибка расчета дохода"}
+	}
+
+	// Считаем утвержденные расходы за период
+	var expenses float64
+	err = q.QueryRow(`
+		SELECT COALESCE(SUM(amount), 0) 
+		FROM charges 
+		WHERE charge_date BETWEEN $1 AND $2 AND status = 'approved'
+	`, startDate, endDate).Scan(&expenses)
+
+	if err != nil {
+		return nil, &apiError{http.StatusInternalServerError, "Ошибка расчета расходов"}
+	}
+
+	// Рассчитываем прибыль
+	profit := revenue - expenses
+
+	return map[string]float64{
+		"profit":        profit,
+		"revenue":       revenue,
+		"gross_revenue": grossRevenue,
+		"discounts":     discounts,
+		"tax":           tax,
+		"expenses":      expenses,
+	}, nil
+}
+
+// GetProfitReport возвращает отчет по прибыли за месяц.
+// Фильтр location_id ограничивает доходы продажами локации, расходы учитываются общие.
+// С параметром compare_to возвращает также показатели периода сравнения и отклонения по каждому.
 func (h *ReportsHandler) GetProfitReport(c *gin.Context) {
-	month := c.Query("month")
-	year := c.Query("year")
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
 
-	if month == "" || year == "" {
+	startDate, ok := parseMonth(c)
+	if !ok {
+		return
+	}
+	endDate := startDate.AddDate(0, 1, 0).Add(-time.Nanosecond)
+
+	compareStart, compareEnd, compare, ok := parseCompareRange(c, startDate, endDate)
+	if !ok {
+		return
+	}
+
+	report, apiErr := profitTotals(h.DB, startDate, endDate, locationID)
+	if apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	if !compare {
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Data:    report,
+		})
+		return
+	}
+
+	compareReport, apiErr := profitTotals(h.DB, compareStart, compareEnd, locationID)
+	if apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	deltas := make(map[string]models.Delta, len(report))
+	for metric, value := range report {
+		deltas[metric] = compareDelta(value, compareReport[metric])
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"period":         reportPeriod(startDate, endDate),
+			"compare_period": reportPeriod(compareStart, compareEnd),
+			"current":        report,
+			"compare":        compareReport,
+			"delta":          deltas,
+		},
+	})
+}
+
+// GetTopProductsReport возвращает топ-5 товаров по доходу за период,
+// с параметром category_id - только среди товаров категории и ее подкатегорий,
+// с параметром location_id - по продажам локации.
+// С параметром compare_to возвращает объединение топ-5 обоих периодов: для каждого товара -
+// доход и место в обоих периодах и отклонение, так что видны и выбывшие из топа товары.
+func (h *ReportsHandler) GetTopProductsReport(c *gin.Context) {
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	categoryID, ok := queryInt(c, "category_id")
+	if !ok {
+		return
+	}
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
+
+	compareStart, compareEnd, compare, ok := parseCompareRange(c, startDate, endDate)
+	if !ok {
+		return
+	}
+	var compareStartArg, compareEndArg *time.Time
+	if compare {
+		compareStartArg, compareEndArg = &compareStart, &compareEnd
+	}
+
+	// Получаем топ-5 товаров по доходу, при сравнении - вместе с топ-5 периода сравнения.
+	// Без сравнения доход за период сравнения нулевой.
+	rows, err := h.DB.Query(`
+		WITH totals AS (
+			SELECT 
+				w.id,
+				w.name,
+				COALESCE(SUM(s.amount) FILTER (WHERE s.sale_date BETWEEN $1 AND $2), 0) as revenue,
+				COALESCE(SUM(s.amount) FILTER (WHERE s.sale_date BETWEEN $5 AND $6), 0) as compare_revenue
+			FROM warehouses w
+			LEFT JOIN sales s ON w.id = s.warehouse_id
+				AND (s.sale_date BETWEEN $1 AND $2 OR s.sale_date BETWEEN $5 AND $6)
+				AND ($4::int IS NULL OR s.location_id = $4)
+			WHERE `+categoryFilter("w.category_id", 3)+`
+			GROUP BY w.id, w.name
+		), ranked AS (
+			SELECT *,
+				ROW_NUMBER() OVER (ORDER BY revenue DESC, id) as rank,
+				ROW_NUMBER() OVER (ORDER BY compare_revenue DESC, id) as compare_rank
+			FROM totals
+		)
+		SELECT id, name, revenue, compare_revenue, rank, compare_rank
+		FROM ranked
+		WHERE rank <= 5 OR ($5::timestamp IS NOT NULL AND compare_rank <= 5)
+		ORDER BY rank
+	`, startDate, endDate, categoryID, locationID, compareStartArg, compareEndArg)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения топ товаров",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var topProducts []map[string]interface{}
+	for rows.Next() {
+		var productID, rank, compareRank int
+		var productName string
+		var revenue, compareRevenue float64
+
+		if err := rows.Scan(&productID, &productName, &revenue, &compareRevenue, &rank, &compareRank); err != nil {
+			continue
+		}
+
+		product := map[string]interface{}{
+			"id":      productID,
+			"name":    productName,
+			"revenue": revenue,
+		}
+		if compare {
+			product["rank"] = rank
+			product["compare_revenue"] = compareRevenue
+			product["compare_rank"] = compareRank
+			product["delta"] = compareDelta(revenue, compareRevenue)
+		}
+		topProducts = append(topProducts, product)
+	}
+
+	if !compare {
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Data:    topProducts,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"period":         reportPeriod(startDate, endDate),
+			"compare_period": reportPeriod(compareStart, compareEnd),
+			"products":       topProducts,
+		},
+	})
+}
+
+// GetTopCustomersReport возвращает топ покупателей по сумме покупок за период
+func (h *ReportsHandler) GetTopCustomersReport(c *gin.Context) {
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
+
+	limit := 5
+	if limitStr := c.Query("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l < 1 || l > 100 {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "Неверное значение limit",
+			})
+			return
+		}
+		limit = l
+	}
+
+	rows, err := h.DB.Query(`
+		SELECT
+			cu.id,
+			cu.name,
+			COUNT(s.id) as purchases,
+			COALESCE(SUM(s.amount), 0) as revenue
+		FROM customers cu
+		JOIN sales s ON cu.id = s.customer_id AND s.sale_date BETWEEN $1 AND $2
+			AND ($4::int IS NULL OR s.location_id = $4)
+		GROUP BY cu.id, cu.name
+		ORDER BY revenue DESC
+		LIMIT $3
+	`, startDate, endDate, limit, locationID)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения топ покупателей",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var topCustomers []map[string]interface{}
+	for rows.Next() {
+		var customerID, purchases int
+		var customerName string
+		var revenue float64
+
+		if err := rows.Scan(&customerID, &customerName, &purchases, &revenue); err != nil {
+			continue
+		}
+
+		topCustomers = append(topCustomers, map[string]interface{}{
+			"id":        customerID,
+			"name":      customerName,
+			"purchases": purchases,
+			"revenue":   revenue,
+		})
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    topCustomers,
+	})
+}
+
+// GetDiscountsReport возвращает сумму скидок за период в разрезе промоакций.
+// Ручные скидки без акции попадают в строку с promotion_id = null.
+func (h *ReportsHandler) GetDiscountsReport(c *gin.Context) {
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
+
+	rows, err := h.DB.Query(`
+		SELECT
+			s.promotion_id,
+			COALESCE(p.name, 'Ручная скидка') as name,
+			COUNT(*) as sales_count,
+			COALESCE(SUM(COALESCE(s.subtotal, s.amount)), 0) as gross_revenue,
+			COALESCE(SUM(s.discount_amount), 0) as discounts
+		FROM sales s
+		LEFT JOIN promotions p ON p.id = s.promotion_id
+		WHERE s.sale_date BETWEEN $1 AND $2 AND s.discount_amount > 0
+			AND ($3::int IS NULL OR s.location_id = $3)
+		GROUP BY s.promotion_id, p.name
+		ORDER BY discounts DESC
+	`, startDate, endDate, locationID)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения отчета по скидкам",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var report []map[string]interface{}
+	for rows.Next() {
+		var promotionID *int
+		var name string
+		var salesCount int
+		var grossRevenue, discounts float64
+
+		if err := rows.Scan(&promotionID, &name, &salesCount, &grossRevenue, &discounts); err != nil {
+			continue
+		}
+
+		report = append(report, map[string]interface{}{
+			"promotion_id":  promotionID,
+			"name":          name,
+			"sales_count":   salesCount,
+			"gross_revenue": grossRevenue,
+			"discounts":     discounts,
+		})
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// GetPaymentMethodsReport возвращает поступления за период в разрезе способов оплаты
+func (h *ReportsHandler) GetPaymentMethodsReport(c *gin.Context) {
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
+
+	rows, err := h.DB.Query(`
+		SELECT
+			p.method,
+			COUNT(DISTINCT p.sale_id) as sales_count,
+			COALESCE(SUM(p.amount), 0) as amount
+		FROM sale_payments p
+		JOIN sales s ON s.id = p.sale_id
+		WHERE s.sale_date BETWEEN $1 AND $2 AND ($3::int IS NULL OR s.location_id = $3)
+		GROUP BY p.method
+		ORDER BY amount DESC
+	`, startDate, endDate, locationID)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения отчета по способам оплаты",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var report []map[string]interface{}
+	for rows.Next() {
+		var method string
+		var salesCount int
+		var amount float64
+
+		if err := rows.Scan(&method, &salesCount, &amount); err != nil {
+			continue
+		}
+
+		report = append(report, map[string]interface{}{
+			"method":      method,
+			"sales_count": salesCount,
+			"amount":      amount,
+		})
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// GetZReport возвращает итоги кассовой смены (Z-отчет) за день по кассирам.
+// Обычный пользователь видит только свою смену, администратор - любую или все сразу.
+func (h *ReportsHandler) GetZReport(c *gin.Context) {
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
+
+	day := time.Now()
+	if dateStr := c.Query("date"); dateStr != "" {
+		d, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "Неверный формат даты",
+			})
+			return
+		}
+		day = d
+	}
+
+	username := c.GetString("username")
+	cashier := c.Query("cashier")
+	if !isAdmin(c) {
+		if cashier != "" && cashier != username {
+			c.JSON(http.StatusForbidden, models.APIResponse{
+				Success: false,
+				Error:   "Недостаточно прав для просмотра смены другого кассира",
+			})
+			return
+		}
+		cashier = username
+	}
+
+	startDate := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	endDate := startDate.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	rows, err := h.DB.Query(`
+		SELECT
+			COALESCE(cashier, '') as cashier,
+			COUNT(*) as sales_count,
+			COALESCE(SUM(quantity), 0) as items,
+			COALESCE(SUM(COALESCE(subtotal, amount)), 0) as gross_revenue,
+			COALESCE(SUM(discount_amount), 0) as discounts,
+			COALESCE(SUM(amount), 0) as total
+		FROM sales
+		WHERE sale_date BETWEEN $1 AND $2 AND ($3 = '' OR cashier = $3)
+			AND ($4::int IS NULL OR location_id = $4)
+		GROUP BY COALESCE(cashier, '')
+		ORDER BY cashier
+	`, startDate, endDate, cashier, locationID)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка формирования Z-отчета",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var shifts []map[string]interface{}
+	byCashier := make(map[string]map[string]float64)
+	for rows.Next() {
+		var name string
+		var salesCount, items int
+		var grossRevenue, discounts, total float64
+
+		if err := rows.Scan(&name, &salesCount, &items, &grossRevenue, &discounts, &total); err != nil {
+			continue
+		}
+
+		payments := map[string]float64{"cash": 0, "card": 0, "transfer": 0, "gift_card": 0}
+		byCashier[name] = payments
+		shifts = append(shifts, map[string]interface{}{
+			"cashier":       name,
+			"date":          startDate.Format("2006-01-02"),
+			"sales_count":   salesCount,
+			"items":         items,
+			"gross_revenue": grossRevenue,
+			"discounts":     discounts,
+			"total":         total,
+			"payments":      payments,
+		})
+	}
+
+	paymentRows, err := h.DB.Query(`
+		SELECT COALESCE(s.cashier, ''), p.method, COALESCE(SUM(p.amount), 0)
+		FROM sale_payments p
+		JOIN sales s ON s.id = p.sale_id
+		WHERE s.sale_date BETWEEN $1 AND $2 AND ($3 = '' OR s.cashier = $3)
+			AND ($4::int IS NULL OR s.location_id = $4)
+		GROUP BY COALESCE(s.cashier, ''), p.method
+	`, startDate, endDate, cashier, locationID)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка формирования Z-отчета",
+		})
+		return
+	}
+	defer paymentRows.Close()
+
+	for paymentRows.Next() {
+		var name, method string
+		var amount float64
+		if err := paymentRows.Scan(&name, &method, &amount); err != nil {
+			continue
+		}
+		if payments, ok := byCashier[name]; ok {
+			payments[method] = amount
+		}
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    shifts,
+	})
+}
+
+// GetTaxReport возвращает собранный налог за период в разрезе ставок
+func (h *ReportsHandler) GetTaxReport(c *gin.Context) {
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
+
+	rows, err := h.DB.Query(`
+		SELECT
+			tax_rate,
+			COUNT(*) as sales_count,
+			COALESCE(SUM(COALESCE(net_amount, amount)), 0) as net,
+			COALESCE(SUM(tax_amount), 0) as tax,
+			COALESCE(SUM(amount), 0) as gross
+		FROM sales
+		WHERE sale_date BETWEEN $1 AND $2 AND ($3::int IS NULL OR location_id = $3)
+		GROUP BY tax_rate
+		ORDER BY tax_rate DESC
+	`, startDate, endDate, locationID)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения налогового отчета",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var rates []map[string]interface{}
+	var totalNet, totalTax, totalGross float64
+	for rows.Next() {
+		var rate, net, tax, gross float64
+		var salesCount int
+
+		if err := rows.Scan(&rate, &salesCount, &net, &tax, &gross); err != nil {
+			continue
+		}
+
+		totalNet += net
+		totalTax += tax
+		totalGross += gross
+		rates = append(rates, map[string]interface{}{
+			"tax_rate":    rate,
+			"sales_count": salesCount,
+			"net":         net,
+			"tax":         tax,
+			"gross":       gross,
+		})
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"rates": rates,
+			"total": gin.H{
+				"net":   round2(totalNet),
+				"tax":   round2(totalTax),
+				"gross": round2(totalGross),
+			},
+		},
+	})
+}
+
+// GetShrinkageReport возвращает недостачи и излишки по проведенным инвентаризациям за период
+// в разрезе товаров, стоимость считается по цене на момент проведения
+func (h *ReportsHandler) GetShrinkageReport(c *gin.Context) {
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
+
+	rows, err := h.DB.Query(`
+		SELECT
+			a.warehouse_id,
+			w.name,
+			COALESCE(SUM(-a.quantity) FILTER (WHERE a.quantity < 0), 0) as shortage_quantity,
+			COALESCE(SUM(-a.quantity * a.unit_value) FILTER (WHERE a.quantity < 0), 0) as shortage_value,
+			COALESCE(SUM(a.quantity) FILTER (WHERE a.quantity > 0), 0) as surplus_quantity,
+			COALESCE(SUM(a.quantity * a.unit_value) FILTER (WHERE a.quantity > 0), 0) as surplus_value
+		FROM stock_adjustments a
+		JOIN warehouses w ON w.id = a.warehouse_id
+		WHERE a.reason = 'stocktake' AND a.created_at BETWEEN $1 AND $2
+		  AND ($3::int IS NULL OR a.location_id = $3)
+		GROUP BY a.warehouse_id, w.name
+		ORDER BY shortage_value DESC, w.name
+	`, startDate, endDate, locationID)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения отчета по недостачам",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var products []map[string]interface{}
+	var totalShortage, totalSurplus float64
+	for rows.Next() {
+		var warehouseID, shortageQty, surplusQty int
+		var name string
+		var shortageValue, surplusValue float64
+
+		if err := rows.Scan(&warehouseID, &name, &shortageQty, &shortageValue, &surplusQty, &surplusValue); err != nil {
+			continue
+		}
+
+		totalShortage += shortageValue
+		totalSurplus += surplusValue
+		products = append(products, map[string]interface{}{
+			"warehouse_id":      warehouseID,
+			"product_name":      name,
+			"shortage_quantity": shortageQty,
+			"shortage_value":    round2(shortageValue),
+			"surplus_quantity":  surplusQty,
+			"surplus_value":     round2(surplusValue),
+			"net_value":         round2(surplusValue - shortageValue),
+		})
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"products": products,
+			"total": gin.H{
+				"shortage_value": round2(totalShortage),
+				"surplus_value":  round2(totalSurplus),
+				"net_value":      round2(totalSurplus - totalShortage),
+			},
+		},
+	})
+}
+
+// GetExpiringLotsReport возвращает партии с остатком, срок годности которых истекает
+// в ближайшие days дней (по умолчанию 30), включая уже просроченные
+func (h *ReportsHandler) GetExpiringLotsReport(c *gin.Context) {
+	days, ok := queryInt(c, "days")
+	if !ok {
+		return
+	}
+	if days == nil {
+		d := 30
+		days = &d
+	}
+	if *days < 0 {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
-			Error:   "Не указаны месяц и год",
+			Error:   "Количество дней не может быть отрицательным",
 		})
 		return
 	}
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
 
-	// Преобразуем параметры в числа
-	monthInt, err := strconv.Atoi(month)
-	if err != nil || monthInt < 1 || monthInt > 12 {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
+	rows, err := h.DB.Query(`
+		SELECT `+lotColumns+`, lt.expiry_date - CURRENT_DATE as days_left, lt.quantity * w.amount as value
+		FROM lots lt
+		JOIN warehouses w ON w.id = lt.warehouse_id
+		WHERE lt.quantity > 0 AND lt.expiry_date <= CURRENT_DATE + $1::int
+		  AND ($2::int IS NULL OR lt.location_id = $2)
+		ORDER BY `+lotOrder,
+		*days, locationID,
+	)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   "Неверный формат месяца",
+			Error:   "Ошибка получения отчета по срокам годности",
 		})
 		return
 	}
+	defer rows.Close()
 
-	yearInt, err := strconv.Atoi(year)
-	if err != nil || yearInt < 2000 || yearInt > 2100 {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Success: false,
-			Error:   "Неверный формат года",
+	var lots []map[string]interface{}
+	var expiredValue, expiringValue float64
+	for rows.Next() {
+		var l models.Lot
+		var daysLeft int
+		var value float64
+
+		if err := rows.Scan(&l.ID, &l.WarehouseID, &l.ProductName, &l.LocationID, &l.LotNumber, &l.ExpiryDate,
+			&l.Quantity, &l.ReceivedQuantity, &l.ReceivedAt, &l.Expired, &daysLeft, &value); err != nil {
+			continue
+		}
+
+		if l.Expired {
+			expiredValue += value
+		} else {
+			expiringValue += value
+		}
+		lots = append(lots, map[string]interface{}{
+			"lot":       l,
+			"days_left": daysLeft,
+			"value":     round2(value),
 		})
-		return
 	}
 
-	// Рассчитываем начальную и конечную даты месяца
-	startDate := time.Date(yearInt, time.Month(monthInt), 1, 0, 0, 0, 0, time.UTC)
-	endDate := startDate.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"days": *days,
+			"lots": lots,
+			"total": gin.H{
+				"expired_value":  round2(expiredValue),
+				"expiring_value": round2(expiringValue),
+			},
+		},
+	})
+}
 
-	// Считаем доход от продаж за месяц
-	var revenue float64
-	err = h.DB.QueryRow(`
-		SELECT COALESCE(SUM(amount), 0) 
-		FROM sales 
-		WHERE sale_date BETWEEN $1 AND $2
-	`, startDate, endDate).Scan(&revenue)
+// GetSupplierSpendReport возвращает расходы за период в разрезе поставщиков.
+// Расходы без поставщика выводятся отдельной строкой с пустым supplier_id.
+func (h *ReportsHandler) GetSupplierSpendReport(c *gin.Context) {
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	rows, err := h.DB.Query(`
+		SELECT
+			c.supplier_id,
+			COALESCE(s.name, 'Без поставщика') as supplier_name,
+			COUNT(*) as charges_count,
+			COALESCE(SUM(c.amount), 0) as total
+		FROM charges c
+		LEFT JOIN suppliers s ON s.id = c.supplier_id
+		WHERE c.charge_date BETWEEN $1 AND $2 AND c.status = 'approved'
+		GROUP BY c.supplier_id, s.name
+		ORDER BY c.supplier_id IS NULL, total DESC
+	`, startDate, endDate)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   "Ошибка расчета дохода",
+			Error:   "Ошибка получения отчета по поставщикам",
 		})
 		return
 	}
+	defer rows.Close()
 
-	// Считаем расходы за месяц
-	var expenses float64
-	err = h.DB.QueryRow(`
-		SELECT COALESCE(SUM(amount), 0) 
-		FROM charges 
-		WHERE charge_date BETWEEN $1 AND $2
-	`, startDate, endDate).Scan(&expenses)
+	var suppliers []map[string]interface{}
+	var total float64
+	for rows.Next() {
+		var supplierID *int
+		var name string
+		var count int
+		var amount float64
+
+		if err := rows.Scan(&supplierID, &name, &count, &amount); err != nil {
+			continue
+		}
+
+		total += amount
+		suppliers = append(suppliers, map[string]interface{}{
+			"supplier_id":   supplierID,
+			"supplier_name": name,
+			"charges_count": count,
+			"total":         round2(amount),
+		})
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"suppliers": suppliers,
+			"total":     round2(total),
+		},
+	})
+}
+
+// GetExpensesReport возвращает расходы за период деревом статей: own - расходы на саму статью,
+// total - вместе со всеми подстатьями. Статьи без расходов за период не выводятся.
+// С параметром expense_item_id отчет строится по этой статье и ее подстатьям.
+func (h *ReportsHandler) GetExpensesReport(c *gin.Context) {
+	expenseItemID, ok := queryInt(c, "expense_item_id")
+	if !ok {
+		return
+	}
+
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
 
+	roots, byID, err := loadExpenseTotals(h.DB, startDate, endDate)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   "Ошибка расчета расходов",
+			Error:   "Ошибка получения отчета по расходам",
 		})
 		return
 	}
 
-	// Рассчитываем прибыль
-	profit := revenue - expenses
+	if expenseItemID != nil {
+		item, ok := byID[*expenseItemID]
+		if !ok {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Статья расходов не найдена",
+			})
+			return
+		}
+		roots = []*models.ExpenseReportItem{item}
+	}
+
+	var total float64
+	for _, root := range roots {
+		total += root.Total
+	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
-		Data: map[string]float64{
-			"profit":   profit,
-			"revenue":  revenue,
-			"expenses": expenses,
+		Data: gin.H{
+			"items": withoutEmptyExpenses(roots),
+			"total": round2(total),
 		},
 	})
 }
 
-// GetTopProductsReport возвращает топ-5 товаров по доходу за период
-func (h *ReportsHandler) GetTopProductsReport(c *gin.Context) {
-	startDateStr := c.Query("start_date")
-	endDateStr := c.Query("end_date")
+// withoutEmptyExpenses убирает из дерева статьи без расходов за период
+func withoutEmptyExpenses(items []*models.ExpenseReportItem) []*models.ExpenseReportItem {
+	result := []*models.ExpenseReportItem{}
+	for _, item := range items {
+		if item.Total == 0 {
+			continue
+		}
+		item.Children = withoutEmptyExpenses(item.Children)
+		if len(item.Children) == 0 {
+			item.Children = nil
+		}
+		result = append(result, item)
+	}
+	return result
+}
 
-	if startDateStr == "" || endDateStr == "" {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
+// GetBudgetReport сравнивает бюджеты статей расходов за месяц с фактическими расходами.
+// Факт статьи включает расходы на все ее подстатьи. В отчет попадают статьи, у которых
+// есть бюджет или расходы за месяц, в порядке дерева статей (level - глубина вложенности).
+func (h *ReportsHandler) GetBudgetReport(c *gin.Context) {
+	startDate, ok := parseMonth(c)
+	if !ok {
+		return
+	}
+
+	roots, _, err := loadExpenseTotals(h.DB, startDate, startDate.AddDate(0, 1, 0).Add(-time.Nanosecond))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   "Не указаны даты начала и окончания",
+			Error:   "Ошибка получения отчета по бюджетам",
 		})
 		return
 	}
 
-	// Парсим даты
-	startDate, err := time.Parse("2006-01-02", startDateStr)
+	rows, err := h.DB.Query(
+		"SELECT expense_item_id, amount, hard_limit FROM budgets WHERE year = $1 AND month = $2",
+		startDate.Year(), int(startDate.Month()),
+	)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   "Неверный формат начальной даты",
+			Error:   "Ошибка получения отчета по бюджетам",
 		})
 		return
 	}
+	defer rows.Close()
 
-	endDate, err := time.Parse("2006-01-02", endDateStr)
+	budgets := make(map[int]models.Budget)
+	for rows.Next() {
+		var b models.Budget
+		if err := rows.Scan(&b.ExpenseItemID, &b.Amount, &b.HardLimit); err != nil {
+			continue
+		}
+		budgets[b.ExpenseItemID] = b
+	}
+
+	var items []map[string]interface{}
+	var totalBudget, totalActual float64
+
+	// Бюджет вложенной статьи входит в бюджет родителя, поэтому в итог идут только верхние бюджеты
+	var walk func(item *models.ExpenseReportItem, level int, underBudget bool)
+	walk = func(item *models.ExpenseReportItem, level int, underBudget bool) {
+		b, hasBudget := budgets[item.ExpenseItemID]
+		if hasBudget || item.Total != 0 {
+			row := map[string]interface{}{
+				"expense_item_id":   item.ExpenseItemID,
+				"expense_item_name": item.Name,
+				"parent_id":         item.ParentID,
+				"level":             level,
+				"budget":            nil,
+				"hard_limit":        false,
+				"actual":            item.Total,
+				"remaining":         nil,
+				"used_percent":      nil,
+				"over_budget":       false,
+			}
+			if hasBudget {
+				row["budget"] = b.Amount
+				row["hard_limit"] = b.HardLimit
+				row["remaining"] = round2(b.Amount - item.Total)
+				row["over_budget"] = item.Total > b.Amount
+				if b.Amount > 0 {
+					row["used_percent"] = round2(item.Total / b.Amount * 100)
+				}
+				if !underBudget {
+					totalBudget += b.Amount
+				}
+			}
+			items = append(items, row)
+		}
+
+		for _, child := range item.Children {
+			walk(child, level+1, underBudget || hasBudget)
+		}
+	}
+
+	for _, root := range roots {
+		walk(root, 0, false)
+		totalActual += root.Total
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: gin.H{
+			"year":  startDate.Year(),
+			"month": int(startDate.Month()),
+			"items": items,
+			"total": gin.H{
+				"budget": round2(totalBudget),
+				"actual": round2(totalActual),
+			},
+		},
+	})
+}
+
+// dashboardTotals считает показатели сводки с начала периода startDate по текущий момент
+func dashboardTotals(q queryer, startDate time.Time, locationID *int) (models.DashboardTotals, error) {
+	var t models.DashboardTotals
+	err := q.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(amount), 0)
+		FROM sales
+		WHERE sale_date >= $1 AND ($2::int IS NULL OR location_id = $2)
+	`, startDate, locationID).Scan(&t.SalesCount, &t.Revenue)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
+		return t, err
+	}
+
+	err = q.QueryRow(`
+		SELECT COALESCE(SUM(amount), 0)
+		FROM charges
+		WHERE charge_date >= $1 AND status = 'approved'
+	`, startDate).Scan(&t.Expenses)
+	if err != nil {
+		return t, err
+	}
+
+	t.Revenue = round2(t.Revenue)
+	t.Expenses = round2(t.Expenses)
+	t.Profit = round2(t.Revenue - t.Expenses)
+	if t.SalesCount > 0 {
+		t.AverageTicket = round2(t.Revenue / float64(t.SalesCount))
+	}
+	return t, nil
+}
+
+// GetDashboardReport возвращает сводку для главной страницы: выручку, расходы, прибыль,
+// число продаж и средний чек за сегодня, неделю (с понедельника) и месяц, число товаров
+// ниже точки заказа и топ-5 товаров месяца. Фильтр location_id ограничивает продажи локацией.
+func (h *ReportsHandler) GetDashboardReport(c *gin.Context) {
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	weekStart := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	periods := gin.H{}
+	for name, startDate := range map[string]time.Time{"today": today, "week": weekStart, "month": monthStart} {
+		totals, err := dashboardTotals(h.DB, startDate, locationID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка расчета показателей сводки",
+			})
+			return
+		}
+		periods[name] = totals
+	}
+
+	lowStock, err := loadLowStock(h.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   "Неверный формат конечной даты",
+			Error:   "Ошибка получения товаров с низким остатком",
 		})
 		return
 	}
 
-	// Добавляем время к конечной дате, чтобы включить весь день
-	endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
-
-	// Получаем топ-5 товаров по доходу
 	rows, err := h.DB.Query(`
-		SELECT 
-			w.id,
-			w.name,
-			COALESCE(SUM(s.amount), 0) as revenue
-		FROM warehouses w
-		LEFT JOIN sales s ON w.id = s.warehouse_id AND s.sale_date BETWEEN $1 AND $2
+		SELECT w.id, w.name, COALESCE(SUM(s.quantity), 0), SUM(s.amount) as revenue
+		FROM sales s
+		JOIN warehouses w ON w.id = s.warehouse_id
+		WHERE s.sale_date >= $1 AND ($2::int IS NULL OR s.location_id = $2)
 		GROUP BY w.id, w.name
 		ORDER BY revenue DESC
 		LIMIT 5
-	`, startDate, endDate)
+	`, monthStart, locationID)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
@@ -157,25 +1072,263 @@ func (h *ReportsHandler) GetTopProductsReport(c *gin.Context) {
 	}
 	defer rows.Close()
 
-	var topProducts []map[string]interface{}
+	topProducts := []models.DashboardProduct{}
 	for rows.Next() {
-		var productID int
-		var productName string
-		var revenue float64
-
-		if err := rows.Scan(&productID, &productName, &revenue); err != nil {
+		var p models.DashboardProduct
+		if err := rows.Scan(&p.WarehouseID, &p.Name, &p.Quantity, &p.Revenue); err != nil {
 			continue
 		}
+		p.Revenue = round2(p.Revenue)
+		topProducts = append(topProducts, p)
+	}
 
-		topProducts = append(topProducts, map[string]interface{}{
-			"id":      productID,
-			"name":    productName,
-			"revenue": revenue,
+	periods["low_stock_count"] = len(lowStock)
+	periods["top_products"] = topProducts
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    periods,
+	})
+}
+
+// GetCashflowReport возвращает движение денег по дням за период: поступления от продаж
+// в разрезе способов оплаты и выплаты по утвержденным расходам. Дни без движения тоже
+// входят в отчет. Фильтр location_id ограничивает поступления продажами локации.
+func (h *ReportsHandler) GetCashflowReport(c *gin.Context) {
+	startDate, endDate, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
+
+	if endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Дата окончания раньше даты начала",
+		})
+		return
+	}
+	if endDate.Sub(startDate) > 366*24*time.Hour {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Период отчета не может быть больше года",
 		})
+		return
+	}
+
+	// Дни ряда передаются отдельными параметрами: приведение $1 и $2 к date сделало бы их датами
+	// и в фильтрах по времени продаж и расходов, и последний день периода обрезался бы до полуночи
+	rows, err := h.DB.Query(`
+		WITH days AS (
+			SELECT d::date as day FROM generate_series($4::date, $5::date, INTERVAL '1 day') d
+		),
+		payments AS (
+			SELECT s.sale_date::date as day, p.method, SUM(p.amount) as amount
+			FROM sale_payments p
+			JOIN sales s ON s.id = p.sale_id
+			WHERE s.sale_date BETWEEN $1 AND $2 AND ($3::int IS NULL OR s.location_id = $3)
+			GROUP BY 1, 2
+		),
+		payouts AS (
+			SELECT charge_date::date as day, SUM(amount) as amount
+			FROM charges
+			WHERE charge_date BETWEEN $1 AND $2 AND status = 'approved'
+			GROUP BY 1
+		)
+		SELECT d.day, p.method, COALESCE(p.amount, 0), COALESCE(o.amount, 0)
+		FROM days d
+		LEFT JOIN payments p ON p.day = d.day
+		LEFT JOIN payouts o ON o.day = d.day
+		ORDER BY d.day, p.method
+	`, startDate, endDate, locationID, startDate, endDate)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения движения денег",
+		})
+		return
+	}
+	defer rows.Close()
+
+	// Строки идут по дням, внутри дня - по способам оплаты; выплаты повторяются в каждой строке дня
+	days := []*models.CashflowDay{}
+	var totalIn, totalOut float64
+	for rows.Next() {
+		var day time.Time
+		var method *string
+		var in, out float64
+		if err := rows.Scan(&day, &method, &in, &out); err != nil {
+			continue
+		}
+
+		date := day.Format("2006-01-02")
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, &models.CashflowDay{Date: date, InByMethod: map[string]float64{}, Out: round2(out)})
+			totalOut += out
+		}
+		d := days[len(days)-1]
+		if method != nil {
+			d.InByMethod[*method] = round2(in)
+			d.In += in
+			totalIn += in
+		}
+	}
+
+	var balance float64
+	for _, d := range days {
+		d.In = round2(d.In)
+		d.Net = round2(d.In - d.Out)
+		balance += d.Net
+		d.Balance = round2(balance)
 	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
-		Data:    topProducts,
+		Data: gin.H{
+			"days": days,
+			"total": gin.H{
+				"in":  round2(totalIn),
+				"out": round2(totalOut),
+				"net": round2(totalIn - totalOut),
+			},
+		},
+	})
+}
+
+// parseMonth разбирает обязательные параметры month и year и возвращает начало месяца.
+// При ошибке сам отвечает клиенту и возвращает false.
+func parseMonth(c *gin.Context) (time.Time, bool) {
+	return parseMonthValues(c, c.Query("month"), c.Query("year"))
+}
+
+// parseMonthValues проверяет месяц и год, переданные в запросе или в пути
+func parseMonthValues(c *gin.Context, month, year string) (time.Time, bool) {
+	if month == "" || year == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Не указаны месяц и год",
+		})
+		return time.Time{}, false
+	}
+
+	// Преобразуем параметры в числа
+	monthInt, err := strconv.Atoi(month)
+	if err != nil || monthInt < 1 || monthInt > 12 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат месяца",
+		})
+		return time.Time{}, false
+	}
+
+	yearInt, err := strconv.Atoi(year)
+	if err != nil || yearInt < 2000 || yearInt > 2100 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат года",
+		})
+		return time.Time{}, false
+	}
+
+	return time.Date(yearInt, time.Month(monthInt), 1, 0, 0, 0, 0, time.UTC), true
+}
+
+// parseCompareRange разбирает параметр compare_to и возвращает период сравнения для отчета
+// за период [startDate, endDate]: previous - предыдущий период той же длины, last_year - тот же
+// период прошлого года, custom - период из compare_start_date и compare_end_date.
+// Без compare_to возвращает false во втором значении. При ошибке сам отвечает клиенту.
+func parseCompareRange(c *gin.Context, startDate, endDate time.Time) (time.Time, time.Time, bool, bool) {
+	// Конец периода - последний момент дня: до начала следующего дня остается gap
+	nextDay := time.Date(endDate.Year(), endDate.Month(), endDate.Day()+1, 0, 0, 0, 0, endDate.Location())
+	gap := nextDay.Sub(endDate)
+
+	switch c.Query("compare_to") {
+	case "":
+		return time.Time{}, time.Time{}, false, true
+
+	case "previous":
+		// Период из целых календарных месяцев сравнивается с тем же числом предыдущих месяцев
+		if startDate.Day() == 1 && nextDay.Day() == 1 {
+			months := (nextDay.Year()-startDate.Year())*12 + int(nextDay.Month()-startDate.Month())
+			return startDate.AddDate(0, -months, 0), startDate.Add(-gap), true, true
+		}
+		days := int(nextDay.Sub(startDate).Hours() / 24)
+		return startDate.AddDate(0, 0, -days), startDate.Add(-gap), true, true
+
+	case "last_year":
+		return startDate.AddDate(-1, 0, 0), nextDay.AddDate(-1, 0, 0).Add(-gap), true, true
+
+	case "custom":
+		compareStart, compareEnd, ok := parseDateRangeValues(c, c.Query("compare_start_date"), c.Query("compare_end_date"))
+		return compareStart, compareEnd, true, ok
+	}
+
+	c.JSON(http.StatusBadRequest, models.APIResponse{
+		Success: false,
+		Error:   "Неверный параметр compare_to, допустимо: previous, last_year, custom",
 	})
+	return time.Time{}, time.Time{}, false, false
+}
+
+// compareDelta считает отклонение значения от значения периода сравнения.
+// Процент не считается, если в периоде сравнения значение нулевое.
+func compareDelta(value, compareValue float64) models.Delta {
+	d := models.Delta{Absolute: round2(value - compareValue)}
+	if compareValue != 0 {
+		percent := round2((value - compareValue) / math.Abs(compareValue) * 100)
+		d.Percent = &percent
+	}
+	return d
+}
+
+// reportPeriod возвращает границы периода отчета датами
+func reportPeriod(startDate, endDate time.Time) gin.H {
+	return gin.H{
+		"start_date": startDate.Format("2006-01-02"),
+		"end_date":   endDate.Format("2006-01-02"),
+	}
+}
+
+// parseDateRange разбирает параметры start_date и end_date в формате YYYY-MM-DD.
+// Конечная дата включается целиком. При ошибке сам отвечает клиенту и возвращает false.
+func parseDateRange(c *gin.Context) (time.Time, time.Time, bool) {
+	return parseDateRangeValues(c, c.Query("start_date"), c.Query("end_date"))
+}
+
+// parseDateRangeValues проверяет даты начала и окончания периода
+func parseDateRangeValues(c *gin.Context, startDateStr, endDateStr string) (time.Time, time.Time, bool) {
+	if startDateStr == "" || endDateStr == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Не указаны даты начала и окончания",
+		})
+		return time.Time{}, time.Time{}, false
+	}
+
+	startDate, err := time.Parse("2006-01-02", startDateStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат начальной даты",
+		})
+		return time.Time{}, time.Time{}, false
+	}
+
+	endDate, err := time.Parse("2006-01-02", endDateStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат конечной даты",
+		})
+		return time.Time{}, time.Time{}, false
+	}
+
+	// Добавляем время к конечной дате, чтобы включить весь день
+	endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+
+	return startDate, endDate, true
 }
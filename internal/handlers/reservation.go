@@ -0,0 +1,393 @@
+// handlers/reservations.go
+package handlers
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"store_app/internal/config"
+	"store_app/internal/models"
+	"store_app/internal/notify"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReservationsHandler struct {
+	DB       *sql.DB
+	Notifier notify.Notifier
+}
+
+func NewReservationsHandler(db *sql.DB) *ReservationsHandler {
+	return &ReservationsHandler{DB: db, Notifier: notify.New(db, config.Load().Alerts)}
+}
+
+type reservationRequest struct {
+	WarehouseID int        `json:"warehouse_id" binding:"required"`
+	LocationID  *int       `json:"location_id"`
+	CustomerID  *int       `json:"customer_id"`
+	Quantity    int        `json:"quantity" binding:"required,min=1"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	Note        string     `json:"note"`
+}
+
+const reservationColumns = `id, warehouse_id, location_id, customer_id, quantity, status, note, expires_at, sale_id,
+        COALESCE(created_by, ''), created_at`
+
+func scanReservation(row interface{ Scan(...interface{}) error }, r *models.Reservation) error {
+	return row.Scan(&r.ID, &r.WarehouseID, &r.LocationID, &r.CustomerID, &r.Quantity, &r.Status, &r.Note,
+		&r.ExpiresAt, &r.SaleID, &r.CreatedBy, &r.CreatedAt)
+}
+
+// GetReservations возвращает резервы, поддерживает фильтры status, warehouse_id и location_id
+func (h *ReservationsHandler) GetReservations(c *gin.Context) {
+	warehouseID, ok := queryInt(c, "warehouse_id")
+	if !ok {
+		return
+	}
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
+
+	rows, err := h.DB.Query(`
+        SELECT `+reservationColumns+`
+        FROM reservations
+        WHERE ($1 = '' OR status = $1)
+          AND ($2::int IS NULL OR warehouse_id = $2)
+          AND ($3::int IS NULL OR location_id = $3)
+        ORDER BY created_at DESC
+    `, c.Query("status"), warehouseID, locationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения резервов",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var reservations []models.Reservation
+	for rows.Next() {
+		var r models.Reservation
+		if err := scanReservation(rows, &r); err != nil {
+			continue
+		}
+		reservations = append(reservations, r)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    reservations,
+	})
+}
+
+// GetReservation возвращает резерв по ID
+func (h *ReservationsHandler) GetReservation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID резерва",
+		})
+		return
+	}
+
+	var r models.Reservation
+	err = scanReservation(h.DB.QueryRow("SELECT "+reservationColumns+" FROM reservations WHERE id = $1", id), &r)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Резерв не найден",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка получения резерва",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    r,
+	})
+}
+
+// CreateReservation резервирует товар на локации до оплаты заказа.
+// Без expires_at резерв действует RESERVATION_TTL.
+func (h *ReservationsHandler) CreateReservation(c *gin.Context) {
+	var req reservationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	expiresAt := time.Now().Add(config.Load().Reservations.TTL)
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "Срок резерва должен быть в будущем",
+			})
+			return
+		}
+		expiresAt = *req.ExpiresAt
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка начала транзакции",
+		})
+		return
+	}
+
+	r, apiErr := createReservation(tx, req, expiresAt, c.GetString("username"))
+	if apiErr != nil {
+		tx.Rollback()
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка коммита транзакции",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    r,
+		Message: "Товар успешно зарезервирован",
+	})
+}
+
+// createReservation проверяет доступный остаток и создает резерв в транзакции tx
+func createReservation(tx *sql.Tx, req reservationRequest, expiresAt time.Time, createdBy string) (models.Reservation, *apiError) {
+	var r models.Reservation
+
+	if apiErr := checkCustomer(tx, req.CustomerID); apiErr != nil {
+		return r, apiErr
+	}
+
+	locationID, apiErr := resolveLocation(tx, req.LocationID)
+	if apiErr != nil {
+		return r, apiErr
+	}
+
+	var isActive bool
+	err := tx.QueryRow("SELECT is_active FROM warehouses WHERE id = $1", req.WarehouseID).Scan(&isActive)
+	if err == sql.ErrNoRows {
+		return r, &apiError{http.StatusNotFound, "Товар не найден"}
+	}
+	if err != nil {
+		return r, &apiError{http.StatusInternalServerError, "Ошибка проверки товара"}
+	}
+	if !isActive {
+		return r, &apiError{http.StatusBadRequest, "Товар снят с продажи"}
+	}
+
+	available, err := availableStock(tx, req.WarehouseID, locationID)
+	if err != nil {
+		return r, &apiError{http.StatusInternalServerError, "Ошибка проверки остатка"}
+	}
+	if available < req.Quantity {
+		return r, &apiError{http.StatusBadRequest, "Недостаточно свободного товара для резерва"}
+	}
+
+	err = scanReservation(tx.QueryRow(`
+        INSERT INTO reservations (warehouse_id, location_id, customer_id, quantity, note, expires_at, created_by)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING `+reservationColumns,
+		req.WarehouseID, locationID, req.CustomerID, req.Quantity, req.Note, expiresAt, createdBy,
+	), &r)
+	if err != nil {
+		return r, &apiError{http.StatusInternalServerError, "Ошибка создания резерва"}
+	}
+	return r, nil
+}
+
+// ReleaseReservation досрочно снимает действующий резерв
+func (h *ReservationsHandler) ReleaseReservation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID резерва",
+		})
+		return
+	}
+
+	var r models.Reservation
+	err = scanReservation(h.DB.QueryRow(`
+        UPDATE reservations SET status = 'released'
+        WHERE id = $1 AND status = 'active'
+        RETURNING `+reservationColumns,
+		id,
+	), &r)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.reservationNotActive(c, id)
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка снятия резерва",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    r,
+		Message: "Резерв снят",
+	})
+}
+
+// ConvertReservation оформляет продажу по резерву: зарезервированный товар списывается,
+// скидка и оплаты передаются так же, как при обычной продаже
+func (h *ReservationsHandler) ConvertReservation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID резерва",
+		})
+		return
+	}
+
+	// Тело запроса необязательно: без него продажа оплачивается наличными без ручной скидки
+	var req struct {
+		Discount *discountRequest `json:"discount"`
+		Payments []paymentRequest `json:"payments" binding:"omitempty,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка начала транзакции",
+		})
+		return
+	}
+
+	// Резерв закрывается до оформления продажи, чтобы его количество стало доступным для нее
+	var r models.Reservation
+	err = scanReservation(tx.QueryRow(`
+        UPDATE reservations SET status = 'converted'
+        WHERE id = $1 AND status = 'active' AND expires_at > CURRENT_TIMESTAMP
+        RETURNING `+reservationColumns,
+		id,
+	), &r)
+
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			h.reservationNotActive(c, id)
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка получения резерва",
+			})
+		}
+		return
+	}
+
+	saleID, lowStock, apiErr := createSale(tx, c, saleRequest{
+		WarehouseID: r.WarehouseID,
+		CustomerID:  r.CustomerID,
+		LocationID:  &r.LocationID,
+		Quantity:    r.Quantity,
+		Discount:    req.Discount,
+		Payments:    req.Payments,
+	})
+	if apiErr != nil {
+		tx.Rollback()
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	if _, err := tx.Exec("UPDATE reservations SET sale_id = $1 WHERE id = $2", saleID, id); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка обновления резерва",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка коммита транзакции",
+		})
+		return
+	}
+
+	if lowStock != nil {
+		notify.Send(h.Notifier, *lowStock)
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    loadSale(h.DB, saleID),
+		Message: "Продажа по резерву успешно создана",
+	})
+}
+
+// reservationNotActive отвечает 404, если резерва нет, и 409, если он уже закрыт или истек
+func (h *ReservationsHandler) reservationNotActive(c *gin.Context, id int) {
+	var status string
+	var expired bool
+	err := h.DB.QueryRow(
+		"SELECT status, expires_at <= CURRENT_TIMESTAMP FROM reservations WHERE id = $1",
+		id,
+	).Scan(&status, &expired)
+
+	switch {
+	case err == sql.ErrNoRows:
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error:   "Резерв не найден",
+		})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения резерва",
+		})
+	case status == "active" && expired:
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Error:   "Срок резерва истек",
+		})
+	default:
+		c.JSON(http.StatusConflict, models.APIResponse{
+			Success: false,
+			Error:   "Резерв уже закрыт (статус " + status + ")",
+		})
+	}
+}
@@ -3,29 +3,96 @@ package handlers
 
 import (
 	"database/sql"
+	"fmt"
 	"net/http"
+	"store_app/internal/config"
+	"store_app/internal/ledger"
 	"store_app/internal/models"
+	"store_app/internal/notify"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 )
 
 type SalesHandler struct {
-	DB *sql.DB
+	DB       *sql.DB
+	Notifier notify.Notifier
 }
 
 func NewSalesHandler(db *sql.DB) *SalesHandler {
-	return &SalesHandler{DB: db}
+	return &SalesHandler{DB: db, Notifier: notify.New(db, config.Load().Alerts)}
 }
 
-// GetSales возвращает все продажи
+const saleColumns = `id, warehouse_id, customer_id, location_id, promotion_id, quantity, COALESCE(subtotal, amount), discount_amount,
+        tax_rate, COALESCE(net_amount, amount), tax_amount, amount, COALESCE(cashier, ''), sale_date`
+
+func scanSale(row interface{ Scan(...interface{}) error }, s *models.Sale) error {
+	return row.Scan(&s.ID, &s.WarehouseID, &s.CustomerID, &s.LocationID, &s.PromotionID, &s.Quantity,
+		&s.Subtotal, &s.DiscountAmount, &s.TaxRate, &s.NetAmount, &s.TaxAmount, &s.Amount, &s.Cashier, &s.SaleDate)
+}
+
+// paymentRequest - оплата продажи одним способом
+type paymentRequest struct {
+	Method string  `json:"method" binding:"required,oneof=cash card transfer gift_card"`
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// loadSalePayments загружает оплаты для списка продаж одним запросом
+func loadSalePayments(q queryer, sales []models.Sale) error {
+	if len(sales) == 0 {
+		return nil
+	}
+
+	index := make(map[int]int, len(sales))
+	ids := make([]int64, 0, len(sales))
+	for i, s := range sales {
+		index[s.ID] = i
+		ids = append(ids, int64(s.ID))
+	}
+
+	rows, err := q.Query(
+		"SELECT sale_id, method, amount FROM sale_payments WHERE sale_id = ANY($1) ORDER BY id",
+		pq.Array(ids),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var saleID int
+		var p models.SalePayment
+		if err := rows.Scan(&saleID, &p.Method, &p.Amount); err != nil {
+			return err
+		}
+		i := index[saleID]
+		sales[i].Payments = append(sales[i].Payments, p)
+	}
+	return rows.Err()
+}
+
+// GetSales возвращает все продажи, поддерживает фильтры category_id и location_id
 func (h *SalesHandler) GetSales(c *gin.Context) {
+	categoryID, ok := queryInt(c, "category_id")
+	if !ok {
+		return
+	}
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
+
 	rows, err := h.DB.Query(`
-        SELECT s.id, s.warehouse_id, s.quantity, s.amount, s.sale_date
-        FROM sales s
-        ORDER BY s.sale_date DESC
-    `)
+        SELECT `+saleColumns+`
+        FROM sales
+        WHERE ($1::int IS NULL OR warehouse_id IN (
+            SELECT id FROM warehouses WHERE `+categoryFilter("category_id", 1)+`
+        ))
+          AND ($2::int IS NULL OR location_id = $2)
+        ORDER BY sale_date DESC
+    `, categoryID, locationID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
@@ -38,25 +105,49 @@ func (h *SalesHandler) GetSales(c *gin.Context) {
 	var sales []models.Sale
 	for rows.Next() {
 		var sale models.Sale
-		if err := rows.Scan(&sale.ID, &sale.WarehouseID, &sale.Quantity, &sale.Amount, &sale.SaleDate); err != nil {
+		if err := scanSale(rows, &sale); err != nil {
 			continue
 		}
 		sales = append(sales, sale)
 	}
 
+	if err := loadSalePayments(h.DB, sales); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения оплат продаж",
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    sales,
 	})
 }
 
+// saleRequest - данные для оформления продажи
+type saleRequest struct {
+	WarehouseID int              `json:"warehouse_id" binding:"required"`
+	CustomerID  *int             `json:"customer_id"`
+	LocationID  *int             `json:"location_id"`
+	Quantity    int              `json:"quantity" binding:"required,min=1"`
+	Discount    *discountRequest `json:"discount"`
+	Payments    []paymentRequest `json:"payments" binding:"omitempty,dive"`
+}
+
+// apiError - ошибка с HTTP-статусом, которую можно вернуть клиенту как есть
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return e.Message
+}
+
 // CreateSale создает новую продажу
 func (h *SalesHandler) CreateSale(c *gin.Context) {
-	var req struct {
-		WarehouseID int `json:"warehouse_id" binding:"required"`
-		Quantity    int `json:"quantity" binding:"required,min=1"`
-	}
-
+	var req saleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
@@ -74,92 +165,235 @@ func (h *SalesHandler) CreateSale(c *gin.Context) {
 		return
 	}
 
-	// Проверяем наличие товара
-	var currentQuantity int
-	var productPrice float64
-	err = tx.QueryRow(
-		"SELECT quantity, amount FROM warehouses WHERE id = $1 FOR UPDATE",
-		req.WarehouseID,
-	).Scan(&currentQuantity, &productPrice)
-
-	if err != nil {
+	saleID, lowStock, apiErr := createSale(tx, c, req)
+	if apiErr != nil {
 		tx.Rollback()
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, models.APIResponse{
-				Success: false,
-				Error:   "Товар не найден",
-			})
-		} else {
-			c.JSON(http.StatusInternalServerError, models.APIResponse{
-				Success: false,
-				Error:   "Ошибка проверки товара",
-			})
-		}
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
 		return
 	}
 
-	if currentQuantity < req.Quantity {
-		tx.Rollback()
-		c.JSON(http.StatusBadRequest, models.APIResponse{
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   "Недостаточно товара на складе",
+			Error:   "Ошибка коммита транзакции",
 		})
 		return
 	}
 
-	amount := productPrice * float64(req.Quantity)
+	if lowStock != nil {
+		notify.Send(h.Notifier, *lowStock)
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    loadSale(h.DB, saleID),
+		Message: "Продажа успешно создана",
+	})
+}
+
+// loadSale загружает продажу с оплатами для ответа клиенту
+func loadSale(q queryer, id int) models.Sale {
+	sales := make([]models.Sale, 1)
+	scanSale(q.QueryRow("SELECT "+saleColumns+" FROM sales WHERE id = $1", id), &sales[0])
+	loadSalePayments(q, sales)
+	loadSaleLots(q, &sales[0])
+	return sales[0]
+}
+
+// checkCustomer проверяет покупателя, если документ к нему привязан
+func checkCustomer(q queryer, customerID *int) *apiError {
+	if customerID == nil {
+		return nil
+	}
+
+	var exists bool
+	err := q.QueryRow("SELECT EXISTS(SELECT 1 FROM customers WHERE id = $1)", *customerID).Scan(&exists)
+	if err != nil {
+		return &apiError{http.StatusInternalServerError, "Ошибка проверки покупателя"}
+	}
+	if !exists {
+		return &apiError{http.StatusNotFound, "Покупатель не найден"}
+	}
+	return nil
+}
+
+// createSale оформляет продажу в транзакции tx: проверяет остаток, применяет скидки и налог,
+// сохраняет продажу с оплатами и списывает товар. Возвращает ID продажи и событие
+// о низком остатке, которое нужно отправить после коммита.
+func createSale(tx *sql.Tx, c *gin.Context, req saleRequest) (int, *notify.Event, *apiError) {
+	if apiErr := checkCustomer(tx, req.CustomerID); apiErr != nil {
+		return 0, nil, apiErr
+	}
+
+	// Без указания локации продажа оформляется на основной
+	locationID, apiErr := resolveLocation(tx, req.LocationID)
+	if apiErr != nil {
+		return 0, nil, apiErr
+	}
+
+	now := time.Now()
+	if apiErr := ensurePeriodOpen(tx, now); apiErr != nil {
+		return 0, nil, apiErr
+	}
+
+	// Проверяем товар. Цена берется из истории цен на момент продажи: запланированное
+	// изменение действует с указанного времени, даже если еще не перенесено в товар.
+	var productPrice, taxRate float64
+	var isActive bool
+	err := tx.QueryRow(`
+        SELECT COALESCE((SELECT p.amount FROM price_history p
+                         WHERE p.warehouse_id = w.id AND p.effective_at <= $2
+                         ORDER BY p.effective_at DESC, p.id DESC LIMIT 1), w.amount),
+               w.tax_rate, w.is_active
+        FROM warehouses w
+        WHERE w.id = $1
+    `, req.WarehouseID, now).Scan(&productPrice, &taxRate, &isActive)
+
+	if err == sql.ErrNoRows {
+		return 0, nil, &apiError{http.StatusNotFound, "Товар не найден"}
+	}
+	if err != nil {
+		return 0, nil, &apiError{http.StatusInternalServerError, "Ошибка проверки товара"}
+	}
+
+	if !isActive {
+		return 0, nil, &apiError{http.StatusBadRequest, "Товар снят с продажи"}
+	}
+
+	// Проверяем доступный остаток на локации: зарезервированный товар продать нельзя
+	available, err := availableStock(tx, req.WarehouseID, locationID)
+	if err != nil {
+		return 0, nil, &apiError{http.StatusInternalServerError, "Ошибка проверки остатка"}
+	}
+
+	if available < req.Quantity {
+		return 0, nil, &apiError{http.StatusBadRequest, "Недостаточно товара на складе"}
+	}
+
+	cfg := config.Load()
+	subtotal := round2(productPrice * float64(req.Quantity))
+
+	// Применяем действующую промоакцию с наибольшей скидкой
+	promotion, promotionDiscount, err := bestPromotion(tx, req.WarehouseID, productPrice, req.Quantity, now)
+	if err != nil {
+		return 0, nil, &apiError{http.StatusInternalServerError, "Ошибка расчета скидки"}
+	}
+
+	var promotionID *int
+	if promotion != nil {
+		promotionID = &promotion.ID
+	}
+
+	// Ручная скидка применяется к сумме после акции
+	var manualDiscount float64
+	if req.Discount != nil {
+		manualDiscount = req.Discount.amount(subtotal - promotionDiscount)
+
+		maxPercent := cfg.Sales.MaxDiscountPercent
+		if manualDiscount > subtotal*maxPercent/100 && !isAdmin(c) {
+			return 0, nil, &apiError{
+				http.StatusForbidden,
+				fmt.Sprintf("Скидка более %g%% требует прав администратора", maxPercent),
+			}
+		}
+	}
+
+	// Налог считается от суммы после скидок
+	discount := round2(promotionDiscount + manualDiscount)
+	netAmount, taxAmount, amount := splitTax(subtotal-discount, taxRate, cfg.Sales.PricesIncludeTax)
+
+	payments, apiErr := salePayments(req.Payments, amount)
+	if apiErr != nil {
+		return 0, nil, apiErr
+	}
+
 	var saleID int
 	err = tx.QueryRow(
-		"INSERT INTO sales (warehouse_id, quantity, amount, sale_date) VALUES ($1, $2, $3, $4) RETURNING id",
-		req.WarehouseID, req.Quantity, amount, time.Now(),
+		`INSERT INTO sales (warehouse_id, customer_id, location_id, promotion_id, quantity, subtotal, discount_amount,
+                            tax_rate, net_amount, tax_amount, amount, cashier, sale_date)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id`,
+		req.WarehouseID, req.CustomerID, locationID, promotionID, req.Quantity, subtotal, discount,
+		taxRate, netAmount, taxAmount, amount, c.GetString("username"), now,
 	).Scan(&saleID)
 
 	if err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Error:   "Ошибка создания продажи",
-		})
-		return
+		return 0, nil, &apiError{http.StatusInternalServerError, "Ошибка создания продажи"}
 	}
 
-	// Обновляем количество товара
-	_, err = tx.Exec(
-		"UPDATE warehouses SET quantity = quantity - $1 WHERE id = $2",
-		req.Quantity, req.WarehouseID,
-	)
+	for _, p := range payments {
+		_, err = tx.Exec(
+			"INSERT INTO sale_payments (sale_id, method, amount) VALUES ($1, $2, $3)",
+			saleID, p.Method, p.Amount,
+		)
+		if err != nil {
+			return 0, nil, &apiError{http.StatusInternalServerError, "Ошибка сохранения оплаты"}
+		}
+	}
+
+	if err := ledger.PostSale(tx, saleID); err != nil {
+		return 0, nil, &apiError{http.StatusInternalServerError, "Ошибка проводки продажи"}
+	}
+
+	// Товар, учитываемый партиями, списывается по FEFO: сначала партии с ближайшим сроком годности
+	allocations, err := allocateLots(tx, req.WarehouseID, locationID, req.Quantity, false)
+	if err == errLotsExhausted {
+		return 0, nil, &apiError{http.StatusBadRequest, "Недостаточно товара с неистекшим сроком годности"}
+	}
 	if err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Error:   "Ошибка обновления количества товара",
-		})
-		return
+		return 0, nil, &apiError{http.StatusInternalServerError, "Ошибка списания партий"}
 	}
 
-	if err := tx.Commit(); err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Error:   "Ошибка коммита транзакции",
-		})
-		return
+	for _, a := range allocations {
+		_, err = tx.Exec(
+			"INSERT INTO sale_lots (sale_id, lot_id, quantity) VALUES ($1, $2, $3)",
+			saleID, a.LotID, a.Quantity,
+		)
+		if err != nil {
+			return 0, nil, &apiError{http.StatusInternalServerError, "Ошибка списания партий"}
+		}
 	}
 
-	// Получаем созданную продажу для ответа
-	var sale models.Sale
-	h.DB.QueryRow(
-		"SELECT id, warehouse_id, quantity, amount, sale_date FROM sales WHERE id = $1",
-		saleID,
-	).Scan(&sale.ID, &sale.WarehouseID, &sale.Quantity, &sale.Amount, &sale.SaleDate)
+	// Списываем товар с локации
+	if err := adjustStock(tx, req.WarehouseID, locationID, -req.Quantity); err != nil {
+		return 0, nil, &apiError{http.StatusInternalServerError, "Ошибка обновления количества товара"}
+	}
 
-	c.JSON(http.StatusCreated, models.APIResponse{
-		Success: true,
-		Data:    sale,
-		Message: "Продажа успешно создана",
-	})
+	// Проверяем, не пора ли дозаказать товар
+	lowStock, err := lowStockEvent(tx, req.WarehouseID, req.Quantity)
+	if err != nil {
+		return 0, nil, &apiError{http.StatusInternalServerError, "Ошибка проверки остатка"}
+	}
+
+	return saleID, lowStock, nil
+}
+
+// salePayments проверяет, что оплаты покрывают сумму продажи. Без указания оплат продажа
+// считается оплаченной наличными целиком, а полностью оплаченная скидкой - без оплат вовсе.
+func salePayments(payments []paymentRequest, amount float64) ([]paymentRequest, *apiError) {
+	if len(payments) == 0 {
+		if amount == 0 {
+			return nil, nil
+		}
+		return []paymentRequest{{Method: "cash", Amount: amount}}, nil
+	}
+
+	var paid float64
+	for _, p := range payments {
+		paid += p.Amount
+	}
+	if round2(paid) != amount {
+		return nil, &apiError{
+			http.StatusBadRequest,
+			fmt.Sprintf("Сумма оплат (%.2f) не совпадает с суммой продажи (%.2f)", paid, amount),
+		}
+	}
+	return payments, nil
 }
 
-// DeleteSale удаляет продажу
+// DeleteSale удаляет продажу и возвращает товар на остаток, если ее период не закрыт
 func (h *SalesHandler) DeleteSale(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -180,10 +414,12 @@ func (h *SalesHandler) DeleteSale(c *gin.Context) {
 	}
 
 	var warehouseID, quantity int
+	var locationID *int
+	var saleDate time.Time
 	err = tx.QueryRow(
-		"SELECT warehouse_id, quantity FROM sales WHERE id = $1 FOR UPDATE",
+		"SELECT warehouse_id, quantity, location_id, sale_date FROM sales WHERE id = $1 FOR UPDATE",
 		id,
-	).Scan(&warehouseID, &quantity)
+	).Scan(&warehouseID, &quantity, &locationID, &saleDate)
 
 	if err != nil {
 		tx.Rollback()
@@ -201,8 +437,47 @@ func (h *SalesHandler) DeleteSale(c *gin.Context) {
 		return
 	}
 
-	// Удаляем продажу
-	_, err = tx.Exec("DELETE FROM sales WHERE id = $1", id)
+	// Возврат проводится датой продажи и в закрытом периоде запрещен
+	if apiErr := ensurePeriodOpen(tx, saleDate); apiErr != nil {
+		tx.Rollback()
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	// Товар возвращается на локацию продажи. Ее остаток блокируется раньше партий -
+	// в том же порядке, что при продаже, иначе возможна взаимоблокировка
+	if locationID == nil {
+		defaultID, err := defaultLocationID(tx)
+		if err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка возврата товара",
+			})
+			return
+		}
+		locationID = &defaultID
+	}
+
+	// Сторнируем проводки продажи и возвращаем товар в партии, из которых он был списан
+	_, err = lockStock(tx, warehouseID, *locationID)
+	if err == nil {
+		err = ledger.PostRefund(tx, id, saleDate)
+	}
+	if err == nil {
+		_, err = tx.Exec(`
+            UPDATE lots SET quantity = lots.quantity + sl.quantity
+            FROM sale_lots sl
+            WHERE sl.lot_id = lots.id AND sl.sale_id = $1
+        `, id)
+	}
+	if err == nil {
+		// Удаляем продажу
+		_, err = tx.Exec("DELETE FROM sales WHERE id = $1", id)
+	}
 	if err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
@@ -212,12 +487,8 @@ func (h *SalesHandler) DeleteSale(c *gin.Context) {
 		return
 	}
 
-	// Возвращаем товар на склад
-	_, err = tx.Exec(
-		"UPDATE warehouses SET quantity = quantity + $1 WHERE id = $2",
-		quantity, warehouseID,
-	)
-	if err != nil {
+	// Возвращаем товар на локацию продажи
+	if err := adjustStock(tx, warehouseID, *locationID, quantity); err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSalePayments(t *testing.T) {
+	tests := []struct {
+		name       string
+		payments   []paymentRequest
+		amount     float64
+		want       []paymentRequest
+		wantStatus int
+	}{
+		{"по умолчанию наличными", nil, 120, []paymentRequest{{Method: "cash", Amount: 120}}, 0},
+		{"нулевой итог без оплат", nil, 0, nil, 0},
+		{
+			"смешанная оплата",
+			[]paymentRequest{{Method: "cash", Amount: 20.1}, {Method: "card", Amount: 99.9}},
+			120,
+			[]paymentRequest{{Method: "cash", Amount: 20.1}, {Method: "card", Amount: 99.9}},
+			0,
+		},
+		{"оплата меньше итога", []paymentRequest{{Method: "card", Amount: 100}}, 120, nil, http.StatusBadRequest},
+		{"оплата при нулевом итоге", []paymentRequest{{Method: "cash", Amount: 1}}, 0, nil, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, apiErr := salePayments(tt.payments, tt.amount)
+			if tt.wantStatus != 0 {
+				if apiErr == nil || apiErr.Status != tt.wantStatus {
+					t.Fatalf("ошибка %v, ожидался статус %d", apiErr, tt.wantStatus)
+				}
+				return
+			}
+			if apiErr != nil {
+				t.Fatalf("неожиданная ошибка: %s", apiErr.Message)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("оплаты %v, ожидалось %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("оплата %d: %v, ожидалось %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFullyDiscountedSale(t *testing.T) {
+	// Скидка 100% дает нулевой итог при любом способе учета НДС, и продажа оформляется без оплат
+	for _, inclusive := range []bool{true, false} {
+		subtotal := 2 * 499.90
+		discount := discountRequest{Type: "percent", Value: 100}.amount(subtotal)
+
+		net, tax, amount := splitTax(subtotal-discount, 20, inclusive)
+		if net != 0 || tax != 0 || amount != 0 {
+			t.Fatalf("цены с НДС %v: итог %v/%v/%v, ожидался нулевой", inclusive, net, tax, amount)
+		}
+
+		payments, apiErr := salePayments(nil, amount)
+		if apiErr != nil {
+			t.Fatalf("цены с НДС %v: %s", inclusive, apiErr.Message)
+		}
+		if len(payments) != 0 {
+			t.Errorf("цены с НДС %v: оплаты %v, ожидалось без оплат", inclusive, payments)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// handlers/stock.go
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"store_app/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// stockLevelColumns - колонки остатка для запросов к stock_levels sl с warehouses w и locations l
+const stockLevelColumns = `sl.warehouse_id, w.name, sl.location_id, l.name, sl.quantity,
+        COALESCE((SELECT SUM(r.quantity) FROM reservations r
+                  WHERE r.warehouse_id = sl.warehouse_id AND r.location_id = sl.location_id
+                    AND r.status = 'active' AND r.expires_at > CURRENT_TIMESTAMP), 0)`
+
+func scanStockLevel(row interface{ Scan(...interface{}) error }, sl *models.StockLevel) error {
+	if err := row.Scan(&sl.WarehouseID, &sl.ProductName, &sl.LocationID, &sl.LocationName, &sl.Quantity, &sl.Reserved); err != nil {
+		return err
+	}
+	sl.Available = sl.Quantity - sl.Reserved
+	return nil
+}
+
+// defaultLocationID возвращает ID основной локации, на которую по умолчанию
+// приходуются товары и оформляются продажи
+func defaultLocationID(q queryer) (int, error) {
+	var id int
+	err := q.QueryRow("SELECT id FROM locations WHERE is_default").Scan(&id)
+	return id, err
+}
+
+// resolveLocation проверяет указанную активную локацию, без указания возвращает основную
+func resolveLocation(q queryer, locationID *int) (int, *apiError) {
+	var id int
+	var err error
+	if locationID != nil {
+		var isActive bool
+		err = q.QueryRow("SELECT id, is_active FROM locations WHERE id = $1", *locationID).Scan(&id, &isActive)
+		if err == nil && !isActive {
+			return 0, &apiError{http.StatusBadRequest, "Локация неактивна"}
+		}
+	} else {
+		id, err = defaultLocationID(q)
+	}
+
+	if err == sql.ErrNoRows {
+		return 0, &apiError{http.StatusNotFound, "Локация не найдена"}
+	}
+	if err != nil {
+		return 0, &apiError{http.StatusInternalServerError, "Ошибка проверки локации"}
+	}
+	return id, nil
+}
+
+// lockStock блокирует строку остатка товара на локации до конца транзакции
+// и возвращает текущее количество (0, если товара на локации не было)
+func lockStock(q queryer, warehouseID, locationID int) (int, error) {
+	var quantity int
+	err := q.QueryRow(
+		"SELECT quantity FROM stock_levels WHERE warehouse_id = $1 AND location_id = $2 FOR UPDATE",
+		warehouseID, locationID,
+	).Scan(&quantity)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return quantity, err
+}
+
+// lockStockRows блокирует остатки товаров на локациях в порядке (warehouse_id, location_id),
+// затем сами товары в порядке ID. Порядок тот же, что у продажи: сначала остаток, потом товар,
+// общий остаток которого пересчитывает триггер sync_warehouse_quantity. Строки остатка, которых
+// еще нет, создаются позже и блокируются при вставке.
+func lockStockRows(q queryer, warehouseIDs []int, locationIDs ...int) error {
+	products := make(pq.Int64Array, len(warehouseIDs))
+	for i, id := range warehouseIDs {
+		products[i] = int64(id)
+	}
+	locations := make(pq.Int64Array, len(locationIDs))
+	for i, id := range locationIDs {
+		locations[i] = int64(id)
+	}
+
+	_, err := q.Exec(`
+        SELECT 1 FROM stock_levels
+        WHERE warehouse_id = ANY($1) AND location_id = ANY($2)
+        ORDER BY warehouse_id, location_id
+        FOR UPDATE
+    `, products, locations)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec("SELECT 1 FROM warehouses WHERE id = ANY($1) ORDER BY id FOR UPDATE", products)
+	return err
+}
+
+// adjustStock изменяет остаток товара на локации на delta.
+// Общий остаток warehouses.quantity пересчитывается триггером sync_warehouse_quantity.
+func adjustStock(q queryer, warehouseID, locationID, delta int) error {
+	_, err := q.Exec(`
+        INSERT INTO stock_levels (warehouse_id, location_id, quantity)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (warehouse_id, location_id)
+        DO UPDATE SET quantity = stock_levels.quantity + EXCLUDED.quantity
+    `, warehouseID, locationID, delta)
+	return err
+}
+
+// reservedStock возвращает количество товара на локации, удерживаемое действующими резервами
+func reservedStock(q queryer, warehouseID, locationID int) (int, error) {
+	var reserved int
+	err := q.QueryRow(`
+        SELECT COALESCE(SUM(quantity), 0)
+        FROM reservations
+        WHERE warehouse_id = $1 AND location_id = $2 AND status = 'active' AND expires_at > CURRENT_TIMESTAMP
+    `, warehouseID, locationID).Scan(&reserved)
+	return reserved, err
+}
+
+// availableStock блокирует остаток товара на локации и возвращает доступное количество:
+// остаток за вычетом действующих резервов
+func availableStock(q queryer, warehouseID, locationID int) (int, error) {
+	onHand, err := lockStock(q, warehouseID, locationID)
+	if err != nil {
+		return 0, err
+	}
+
+	reserved, err := reservedStock(q, warehouseID, locationID)
+	if err != nil {
+		return 0, err
+	}
+	return onHand - reserved, nil
+}
@@ -0,0 +1,499 @@
+// handlers/stocktakes.go
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"store_app/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StocktakesHandler struct {
+	DB *sql.DB
+}
+
+func NewStocktakesHandler(db *sql.DB) *StocktakesHandler {
+	return &StocktakesHandler{DB: db}
+}
+
+const stocktakeColumns = `id, location_id, status, note, COALESCE(created_by, ''), created_at,
+        COALESCE(posted_by, ''), posted_at`
+
+func scanStocktake(row interface{ Scan(...interface{}) error }, s *models.Stocktake) error {
+	return row.Scan(&s.ID, &s.LocationID, &s.Status, &s.Note, &s.CreatedBy, &s.CreatedAt, &s.PostedBy, &s.PostedAt)
+}
+
+// loadStocktakeItems загружает позиции инвентаризации с расхождениями и считает итоги.
+// Пока инвентаризация не проведена, расхождение считается от текущего остатка на локации,
+// после проведения - от зафиксированного учетного остатка.
+func loadStocktakeItems(q queryer, s *models.Stocktake) error {
+	rows, err := q.Query(`
+        SELECT i.warehouse_id, w.name, COALESCE(i.system_quantity, sl.quantity, 0), i.counted_quantity,
+               COALESCE(i.unit_value, w.amount)
+        FROM stocktake_items i
+        JOIN warehouses w ON w.id = i.warehouse_id
+        LEFT JOIN stock_levels sl ON sl.warehouse_id = i.warehouse_id AND sl.location_id = $2
+        WHERE i.stocktake_id = $1
+        ORDER BY w.name
+    `, s.ID, s.LocationID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	summary := models.StocktakeSummary{}
+	s.Items = nil
+	for rows.Next() {
+		var item models.StocktakeItem
+		if err := rows.Scan(&item.WarehouseID, &item.ProductName, &item.SystemQuantity,
+			&item.CountedQuantity, &item.UnitValue); err != nil {
+			return err
+		}
+
+		item.Variance = item.CountedQuantity - item.SystemQuantity
+		item.VarianceValue = round2(float64(item.Variance) * item.UnitValue)
+		if item.Variance < 0 {
+			summary.ShortageQuantity -= item.Variance
+			summary.ShortageValue -= item.VarianceValue
+		} else {
+			summary.SurplusQuantity += item.Variance
+			summary.SurplusValue += item.VarianceValue
+		}
+		s.Items = append(s.Items, item)
+	}
+
+	summary.ShortageValue = round2(summary.ShortageValue)
+	summary.SurplusValue = round2(summary.SurplusValue)
+	summary.NetValue = round2(summary.SurplusValue - summary.ShortageValue)
+	s.Summary = &summary
+	return rows.Err()
+}
+
+// GetStocktakes возвращает инвентаризации, поддерживает фильтры status и location_id
+func (h *StocktakesHandler) GetStocktakes(c *gin.Context) {
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
+
+	rows, err := h.DB.Query(`
+        SELECT `+stocktakeColumns+`
+        FROM stocktakes
+        WHERE ($1 = '' OR status = $1) AND ($2::int IS NULL OR location_id = $2)
+        ORDER BY created_at DESC
+    `, c.Query("status"), locationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения инвентаризаций",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var stocktakes []models.Stocktake
+	for rows.Next() {
+		var s models.Stocktake
+		if err := scanStocktake(rows, &s); err != nil {
+			continue
+		}
+		stocktakes = append(stocktakes, s)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    stocktakes,
+	})
+}
+
+// GetStocktake возвращает инвентаризацию с расхождениями по позициям
+func (h *StocktakesHandler) GetStocktake(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID инвентаризации",
+		})
+		return
+	}
+
+	var s models.Stocktake
+	err = scanStocktake(h.DB.QueryRow("SELECT "+stocktakeColumns+" FROM stocktakes WHERE id = $1", id), &s)
+	if err == nil {
+		err = loadStocktakeItems(h.DB, &s)
+	}
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Инвентаризация не найдена",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка получения инвентаризации",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    s,
+	})
+}
+
+// CreateStocktake открывает инвентаризацию на локации (по умолчанию - основной)
+func (h *StocktakesHandler) CreateStocktake(c *gin.Context) {
+	var req struct {
+		LocationID *int   `json:"location_id"`
+		Note       string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	locationID, apiErr := resolveLocation(h.DB, req.LocationID)
+	if apiErr != nil {
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	var s models.Stocktake
+	err := scanStocktake(h.DB.QueryRow(
+		`INSERT INTO stocktakes (location_id, note, created_by) VALUES ($1, $2, $3)
+         RETURNING `+stocktakeColumns,
+		locationID, req.Note, c.GetString("username"),
+	), &s)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка создания инвентаризации",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    s,
+		Message: "Инвентаризация открыта",
+	})
+}
+
+// SubmitCounts принимает очередную партию подсчетов. Подсчеты одного товара суммируются
+// (товар может лежать на разных полках), с replace=true - заменяют прежнее значение.
+func (h *StocktakesHandler) SubmitCounts(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID инвентаризации",
+		})
+		return
+	}
+
+	var req struct {
+		Replace bool `json:"replace"`
+		Items   []struct {
+			WarehouseID int `json:"warehouse_id" binding:"required"`
+			Quantity    int `json:"quantity" binding:"min=0"`
+		} `json:"items" binding:"required,min=1,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка начала транзакции",
+		})
+		return
+	}
+
+	var s models.Stocktake
+	if apiErr := lockOpenStocktake(tx, id, &s); apiErr != nil {
+		tx.Rollback()
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	for _, item := range req.Items {
+		_, err = tx.Exec(`
+            INSERT INTO stocktake_items (stocktake_id, warehouse_id, counted_quantity)
+            VALUES ($1, $2, $3)
+            ON CONFLICT (stocktake_id, warehouse_id) DO UPDATE
+            SET counted_quantity = CASE WHEN $4 THEN EXCLUDED.counted_quantity
+                                        ELSE stocktake_items.counted_quantity + EXCLUDED.counted_quantity END,
+                counted_at = CURRENT_TIMESTAMP
+        `, id, item.WarehouseID, item.Quantity, req.Replace)
+		if err != nil {
+			tx.Rollback()
+			if isForeignKeyViolation(err) {
+				c.JSON(http.StatusNotFound, models.APIResponse{
+					Success: false,
+					Error:   "Товар " + strconv.Itoa(item.WarehouseID) + " не найден",
+				})
+			} else {
+				c.JSON(http.StatusInternalServerError, models.APIResponse{
+					Success: false,
+					Error:   "Ошибка сохранения подсчетов",
+				})
+			}
+			return
+		}
+	}
+
+	if err := loadStocktakeItems(tx, &s); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения инвентаризации",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка коммита транзакции",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    s,
+		Message: "Подсчеты сохранены",
+	})
+}
+
+// PostStocktake проводит инвентаризацию: фиксирует учетные остатки, приводит остатки
+// на локации к посчитанным и записывает корректировки. Непосчитанные товары не меняются.
+func (h *StocktakesHandler) PostStocktake(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID инвентаризации",
+		})
+		return
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка начала транзакции",
+		})
+		return
+	}
+
+	var s models.Stocktake
+	if apiErr := lockOpenStocktake(tx, id, &s); apiErr != nil {
+		tx.Rollback()
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	// Корректировки остатков проводятся датой проведения инвентаризации
+	if apiErr := ensurePeriodOpen(tx, time.Now()); apiErr != nil {
+		tx.Rollback()
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	if err := postStocktake(tx, &s, c.GetString("username")); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка проведения инвентаризации",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка коммита транзакции",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    s,
+		Message: "Инвентаризация проведена",
+	})
+}
+
+// postStocktake выполняет проведение открытой инвентаризации s в транзакции tx
+func postStocktake(tx *sql.Tx, s *models.Stocktake, username string) error {
+	// Блокируем остатки в порядке ID товара, как и при перемещениях
+	rows, err := tx.Query(`
+        SELECT i.warehouse_id, i.counted_quantity, w.amount
+        FROM stocktake_items i
+        JOIN warehouses w ON w.id = i.warehouse_id
+        WHERE i.stocktake_id = $1
+        ORDER BY i.warehouse_id
+    `, s.ID)
+	if err != nil {
+		return err
+	}
+
+	type count struct {
+		warehouseID, counted int
+		unitValue            float64
+	}
+	var counts []count
+	for rows.Next() {
+		var c count
+		if err := rows.Scan(&c.warehouseID, &c.counted, &c.unitValue); err != nil {
+			rows.Close()
+			return err
+		}
+		counts = append(counts, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, c := range counts {
+		system, err := lockStock(tx, c.warehouseID, s.LocationID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			"UPDATE stocktake_items SET system_quantity = $1, unit_value = $2 WHERE stocktake_id = $3 AND warehouse_id = $4",
+			system, c.unitValue, s.ID, c.warehouseID,
+		)
+		if err != nil {
+			return err
+		}
+
+		variance := c.counted - system
+		if variance == 0 {
+			continue
+		}
+
+		_, err = tx.Exec(`
+            INSERT INTO stock_adjustments (warehouse_id, location_id, quantity, unit_value, reason, stocktake_id, created_by)
+            VALUES ($1, $2, $3, $4, 'stocktake', $5, $6)
+        `, c.warehouseID, s.LocationID, variance, c.unitValue, s.ID, username)
+		if err != nil {
+			return err
+		}
+
+		if err := adjustStock(tx, c.warehouseID, s.LocationID, variance); err != nil {
+			return err
+		}
+	}
+
+	err = scanStocktake(tx.QueryRow(`
+        UPDATE stocktakes SET status = 'posted', posted_by = $1, posted_at = CURRENT_TIMESTAMP
+        WHERE id = $2
+        RETURNING `+stocktakeColumns,
+		username, s.ID,
+	), s)
+	if err != nil {
+		return err
+	}
+
+	return loadStocktakeItems(tx, s)
+}
+
+// CancelStocktake отменяет открытую инвентаризацию без изменения остатков
+func (h *StocktakesHandler) CancelStocktake(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID инвентаризации",
+		})
+		return
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка начала транзакции",
+		})
+		return
+	}
+
+	var s models.Stocktake
+	if apiErr := lockOpenStocktake(tx, id, &s); apiErr != nil {
+		tx.Rollback()
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	if _, err := tx.Exec("UPDATE stocktakes SET status = 'cancelled' WHERE id = $1", id); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка отмены инвентаризации",
+		})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка коммита транзакции",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Инвентаризация отменена",
+	})
+}
+
+// lockOpenStocktake блокирует инвентаризацию до конца транзакции и проверяет, что она открыта
+func lockOpenStocktake(tx *sql.Tx, id int, s *models.Stocktake) *apiError {
+	err := scanStocktake(tx.QueryRow("SELECT "+stocktakeColumns+" FROM stocktakes WHERE id = $1 FOR UPDATE", id), s)
+	if err == sql.ErrNoRows {
+		return &apiError{http.StatusNotFound, "Инвентаризация не найдена"}
+	}
+	if err != nil {
+		return &apiError{http.StatusInternalServerError, "Ошибка получения инвентаризации"}
+	}
+	if s.Status != "open" {
+		return &apiError{http.StatusConflict, "Инвентаризация уже проведена или отменена"}
+	}
+	return nil
+}
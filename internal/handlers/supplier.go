@@ -0,0 +1,501 @@
+// handlers/suppliers.go
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"store_app/internal/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SuppliersHandler struct {
+	DB *sql.DB
+}
+
+func NewSuppliersHandler(db *sql.DB) *SuppliersHandler {
+	return &SuppliersHandler{DB: db}
+}
+
+type supplierRequest struct {
+	Name             string `json:"name" binding:"required"`
+	ContactName      string `json:"contact_name"`
+	Phone            string `json:"phone"`
+	Email            string `json:"email" binding:"omitempty,email"`
+	PaymentTermsDays int    `json:"payment_terms_days" binding:"min=0"`
+	LeadTimeDays     *int   `json:"lead_time_days" binding:"omitempty,min=0"`
+	Notes            string `json:"notes"`
+	IsActive         *bool  `json:"is_active"`
+}
+
+const supplierColumns = `id, name, contact_name, phone, email, payment_terms_days, lead_time_days, notes, is_active, created_at`
+
+func scanSupplier(row interface{ Scan(...interface{}) error }, s *models.Supplier) error {
+	return row.Scan(&s.ID, &s.Name, &s.ContactName, &s.Phone, &s.Email, &s.PaymentTermsDays, &s.LeadTimeDays, &s.Notes,
+		&s.IsActive, &s.CreatedAt)
+}
+
+// productSupplierColumns - колонки для запросов к product_suppliers ps с warehouses w и suppliers s
+const productSupplierColumns = `ps.warehouse_id, w.name, ps.supplier_id, s.name, ps.supplier_sku,
+        ps.last_purchase_price, ps.last_purchased_at, ps.is_primary`
+
+func scanProductSupplier(row interface{ Scan(...interface{}) error }, ps *models.ProductSupplier) error {
+	return row.Scan(&ps.WarehouseID, &ps.ProductName, &ps.SupplierID, &ps.SupplierName, &ps.SupplierSKU,
+		&ps.LastPurchasePrice, &ps.LastPurchasedAt, &ps.IsPrimary)
+}
+
+// supplierPaymentTerms возвращает отсрочку оплаты поставщиков по их ID
+func supplierPaymentTerms(q queryer) (map[int]int, error) {
+	rows, err := q.Query("SELECT id, payment_terms_days FROM suppliers")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	terms := make(map[int]int)
+	for rows.Next() {
+		var id, days int
+		if err := rows.Scan(&id, &days); err != nil {
+			return nil, err
+		}
+		terms[id] = days
+	}
+	return terms, rows.Err()
+}
+
+// checkSupplier проверяет поставщика, если документ к нему привязан
+func checkSupplier(q queryer, supplierID *int) *apiError {
+	if supplierID == nil {
+		return nil
+	}
+
+	var exists bool
+	err := q.QueryRow("SELECT EXISTS(SELECT 1 FROM suppliers WHERE id = $1)", *supplierID).Scan(&exists)
+	if err != nil {
+		return &apiError{http.StatusInternalServerError, "Ошибка проверки поставщика"}
+	}
+	if !exists {
+		return &apiError{http.StatusNotFound, "Поставщик не найден"}
+	}
+	return nil
+}
+
+// GetSuppliers возвращает поставщиков, поддерживает поиск по названию и контакту и фильтр active
+func (h *SuppliersHandler) GetSuppliers(c *gin.Context) {
+	rows, err := h.DB.Query(`
+        SELECT `+supplierColumns+`
+        FROM suppliers
+        WHERE ($1 = '' OR name ILIKE '%' || $1 || '%' OR contact_name ILIKE '%' || $1 || '%')
+          AND ($2 = '' OR is_active = ($2 = 'true'))
+        ORDER BY name
+    `, c.Query("search"), c.Query("active"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения поставщиков",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var suppliers []models.Supplier
+	for rows.Next() {
+		var s models.Supplier
+		if err := scanSupplier(rows, &s); err != nil {
+			continue
+		}
+		suppliers = append(suppliers, s)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    suppliers,
+	})
+}
+
+// GetSupplier возвращает поставщика по ID
+func (h *SuppliersHandler) GetSupplier(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID поставщика",
+		})
+		return
+	}
+
+	var s models.Supplier
+	err = scanSupplier(h.DB.QueryRow("SELECT "+supplierColumns+" FROM suppliers WHERE id = $1", id), &s)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Поставщик не найден",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка получения поставщика",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    s,
+	})
+}
+
+// CreateSupplier создает поставщика
+func (h *SuppliersHandler) CreateSupplier(c *gin.Context) {
+	var req supplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	var s models.Supplier
+	err := scanSupplier(h.DB.QueryRow(
+		`INSERT INTO suppliers (name, contact_name, phone, email, payment_terms_days, lead_time_days, notes, is_active)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, true))
+         RETURNING `+supplierColumns,
+		req.Name, req.ContactName, req.Phone, req.Email, req.PaymentTermsDays, req.LeadTimeDays, req.Notes, req.IsActive,
+	), &s)
+
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Error:   "Поставщик с таким названием уже существует",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка создания поставщика",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    s,
+		Message: "Поставщик успешно создан",
+	})
+}
+
+// UpdateSupplier обновляет поставщика
+func (h *SuppliersHandler) UpdateSupplier(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID поставщика",
+		})
+		return
+	}
+
+	var req supplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	var s models.Supplier
+	err = scanSupplier(h.DB.QueryRow(
+		`UPDATE suppliers
+         SET name = $1, contact_name = $2, phone = $3, email = $4, payment_terms_days = $5,
+             lead_time_days = $6, notes = $7, is_active = COALESCE($8, is_active)
+         WHERE id = $9
+         RETURNING `+supplierColumns,
+		req.Name, req.ContactName, req.Phone, req.Email, req.PaymentTermsDays, req.LeadTimeDays, req.Notes, req.IsActive, id,
+	), &s)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Поставщик не найден",
+			})
+		} else if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Error:   "Поставщик с таким названием уже существует",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка обновления поставщика",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    s,
+		Message: "Поставщик успешно обновлен",
+	})
+}
+
+// DeleteSupplier удаляет поставщика вместе с привязками к товарам.
+// Поставщика, на которого оформлены расходы, можно только деактивировать.
+func (h *SuppliersHandler) DeleteSupplier(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID поставщика",
+		})
+		return
+	}
+
+	result, err := h.DB.Exec("DELETE FROM suppliers WHERE id = $1", id)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Error:   "На поставщика оформлены расходы, его можно только деактивировать",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка удаления поставщика",
+			})
+		}
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error:   "Поставщик не найден",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Поставщик успешно удален",
+	})
+}
+
+// GetSupplierProducts возвращает товары поставщика
+func (h *SuppliersHandler) GetSupplierProducts(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID поставщика",
+		})
+		return
+	}
+
+	h.listProductSuppliers(c, "ps.supplier_id = $1", id)
+}
+
+// GetWarehouseSuppliers возвращает поставщиков товара, основной - первым
+func (h *SuppliersHandler) GetWarehouseSuppliers(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID товара",
+		})
+		return
+	}
+
+	h.listProductSuppliers(c, "ps.warehouse_id = $1", id)
+}
+
+func (h *SuppliersHandler) listProductSuppliers(c *gin.Context, where string, id int) {
+	rows, err := h.DB.Query(`
+        SELECT `+productSupplierColumns+`
+        FROM product_suppliers ps
+        JOIN warehouses w ON w.id = ps.warehouse_id
+        JOIN suppliers s ON s.id = ps.supplier_id
+        WHERE `+where+`
+        ORDER BY ps.is_primary DESC, s.name, w.name
+    `, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения товаров поставщиков",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var links []models.ProductSupplier
+	for rows.Next() {
+		var ps models.ProductSupplier
+		if err := scanProductSupplier(rows, &ps); err != nil {
+			continue
+		}
+		links = append(links, ps)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    links,
+	})
+}
+
+// SetWarehouseSupplier привязывает товар к поставщику или обновляет привязку.
+// Назначение основного поставщика снимает признак с предыдущего.
+func (h *SuppliersHandler) SetWarehouseSupplier(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID товара",
+		})
+		return
+	}
+
+	supplierID, err := strconv.Atoi(c.Param("supplier_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID поставщика",
+		})
+		return
+	}
+
+	var req struct {
+		SupplierSKU       string   `json:"supplier_sku" binding:"max=64"`
+		LastPurchasePrice *float64 `json:"last_purchase_price" binding:"omitempty,min=0"`
+		IsPrimary         bool     `json:"is_primary"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка начала транзакции",
+		})
+		return
+	}
+
+	if req.IsPrimary {
+		_, err = tx.Exec(
+			"UPDATE product_suppliers SET is_primary = false WHERE warehouse_id = $1 AND supplier_id <> $2 AND is_primary",
+			id, supplierID,
+		)
+	}
+	if err == nil {
+		_, err = tx.Exec(`
+            INSERT INTO product_suppliers (warehouse_id, supplier_id, supplier_sku, last_purchase_price, last_purchased_at, is_primary)
+            VALUES ($1, $2, $3, $4, CASE WHEN $4::numeric IS NOT NULL THEN CURRENT_TIMESTAMP END, $5)
+            ON CONFLICT (warehouse_id, supplier_id) DO UPDATE
+            SET supplier_sku = EXCLUDED.supplier_sku,
+                last_purchase_price = COALESCE(EXCLUDED.last_purchase_price, product_suppliers.last_purchase_price),
+                last_purchased_at = COALESCE(EXCLUDED.last_purchased_at, product_suppliers.last_purchased_at),
+                is_primary = EXCLUDED.is_primary
+        `, id, supplierID, req.SupplierSKU, req.LastPurchasePrice, req.IsPrimary)
+	}
+
+	var ps models.ProductSupplier
+	if err == nil {
+		err = scanProductSupplier(tx.QueryRow(`
+            SELECT `+productSupplierColumns+`
+            FROM product_suppliers ps
+            JOIN warehouses w ON w.id = ps.warehouse_id
+            JOIN suppliers s ON s.id = ps.supplier_id
+            WHERE ps.warehouse_id = $1 AND ps.supplier_id = $2
+        `, id, supplierID), &ps)
+	}
+
+	if err != nil {
+		tx.Rollback()
+		if isForeignKeyViolation(err) {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Товар или поставщик не найден",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка привязки поставщика",
+			})
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка коммита транзакции",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    ps,
+		Message: "Поставщик товара сохранен",
+	})
+}
+
+// DeleteWarehouseSupplier удаляет привязку товара к поставщику
+func (h *SuppliersHandler) DeleteWarehouseSupplier(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID товара",
+		})
+		return
+	}
+
+	supplierID, err := strconv.Atoi(c.Param("supplier_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID поставщика",
+		})
+		return
+	}
+
+	result, err := h.DB.Exec(
+		"DELETE FROM product_suppliers WHERE warehouse_id = $1 AND supplier_id = $2",
+		id, supplierID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка удаления поставщика товара",
+		})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error:   "Товар не привязан к поставщику",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Поставщик товара удален",
+	})
+}
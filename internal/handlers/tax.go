@@ -0,0 +1,16 @@
+// handlers/tax.go
+package handlers
+
+// splitTax раскладывает сумму позиции на сумму без налога, налог и итог с налогом.
+// rate - ставка в процентах, inclusive - цена уже включает налог.
+func splitTax(lineAmount, rate float64, inclusive bool) (net, tax, gross float64) {
+	if inclusive {
+		gross = round2(lineAmount)
+		net = round2(gross * 100 / (100 + rate))
+		return net, round2(gross - net), gross
+	}
+
+	net = round2(lineAmount)
+	tax = round2(net * rate / 100)
+	return net, tax, round2(net + tax)
+}
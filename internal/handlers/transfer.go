@@ -0,0 +1,291 @@
+// handlers/transfers.go
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"store_app/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TransfersHandler struct {
+	DB *sql.DB
+}
+
+func NewTransfersHandler(db *sql.DB) *TransfersHandler {
+	return &TransfersHandler{DB: db}
+}
+
+type transferRequest struct {
+	FromLocationID int    `json:"from_location_id" binding:"required"`
+	ToLocationID   int    `json:"to_location_id" binding:"required,nefield=FromLocationID"`
+	Note           string `json:"note"`
+	Items          []struct {
+		WarehouseID int `json:"warehouse_id" binding:"required"`
+		Quantity    int `json:"quantity" binding:"required,min=1"`
+	} `json:"items" binding:"required,min=1,dive"`
+}
+
+// GetTransfers возвращает документы перемещения, с параметром location_id - только затрагивающие локацию
+func (h *TransfersHandler) GetTransfers(c *gin.Context) {
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
+
+	rows, err := h.DB.Query(`
+        SELECT id, from_location_id, to_location_id, note, COALESCE(created_by, ''), created_at
+        FROM stock_transfers
+        WHERE $1::int IS NULL OR from_location_id = $1 OR to_location_id = $1
+        ORDER BY created_at DESC
+    `, locationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения перемещений",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var transfers []models.StockTransfer
+	for rows.Next() {
+		var t models.StockTransfer
+		if err := rows.Scan(&t.ID, &t.FromLocationID, &t.ToLocationID, &t.Note, &t.CreatedBy, &t.CreatedAt); err != nil {
+			continue
+		}
+		transfers = append(transfers, t)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    transfers,
+	})
+}
+
+// GetTransfer возвращает документ перемещения с позициями
+func (h *TransfersHandler) GetTransfer(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID перемещения",
+		})
+		return
+	}
+
+	var t models.StockTransfer
+	err = h.DB.QueryRow(`
+        SELECT id, from_location_id, to_location_id, note, COALESCE(created_by, ''), created_at
+        FROM stock_transfers
+        WHERE id = $1
+    `, id).Scan(&t.ID, &t.FromLocationID, &t.ToLocationID, &t.Note, &t.CreatedBy, &t.CreatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Перемещение не найдено",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка получения перемещения",
+			})
+		}
+		return
+	}
+
+	rows, err := h.DB.Query(
+		"SELECT warehouse_id, quantity FROM stock_transfer_items WHERE transfer_id = $1 ORDER BY id",
+		id,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения позиций перемещения",
+		})
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.StockTransferItem
+		if err := rows.Scan(&item.WarehouseID, &item.Quantity); err != nil {
+			continue
+		}
+		t.Items = append(t.Items, item)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    t,
+	})
+}
+
+// CreateTransfer перемещает товары между локациями одной транзакцией:
+// либо переносятся все позиции, либо ни одна
+func (h *TransfersHandler) CreateTransfer(c *gin.Context) {
+	var req transferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный формат данных",
+		})
+		return
+	}
+
+	// Одинаковые товары объединяем и обрабатываем в порядке ID
+	quantities := make(map[int]int)
+	var warehouseIDs []int
+	for _, item := range req.Items {
+		if _, ok := quantities[item.WarehouseID]; !ok {
+			warehouseIDs = append(warehouseIDs, item.WarehouseID)
+		}
+		quantities[item.WarehouseID] += item.Quantity
+	}
+	sort.Ints(warehouseIDs)
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка начала транзакции",
+		})
+		return
+	}
+
+	if apiErr := ensurePeriodOpen(tx, time.Now()); apiErr != nil {
+		tx.Rollback()
+		c.JSON(apiErr.Status, models.APIResponse{
+			Success: false,
+			Error:   apiErr.Message,
+		})
+		return
+	}
+
+	var active int
+	err = tx.QueryRow(
+		"SELECT COUNT(*) FROM locations WHERE id IN ($1, $2) AND is_active",
+		req.FromLocationID, req.ToLocationID,
+	).Scan(&active)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка проверки локаций",
+		})
+		return
+	}
+	if active != 2 {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Error:   "Локация не найдена или неактивна",
+		})
+		return
+	}
+
+	// Остатки на обеих локациях и товары блокируем заранее в общем порядке, чтобы встречные
+	// перемещения и продажи на локации-получателе не приводили к взаимоблокировке
+	if err := lockStockRows(tx, warehouseIDs, req.FromLocationID, req.ToLocationID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка проверки остатка",
+		})
+		return
+	}
+
+	for _, warehouseID := range warehouseIDs {
+		available, err := availableStock(tx, warehouseID, req.FromLocationID)
+		if err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка проверки остатка",
+			})
+			return
+		}
+		if available < quantities[warehouseID] {
+			tx.Rollback()
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Недостаточно товара %d на локации-источнике: доступно %d", warehouseID, available),
+			})
+			return
+		}
+	}
+
+	t := models.StockTransfer{
+		FromLocationID: req.FromLocationID,
+		ToLocationID:   req.ToLocationID,
+		Note:           req.Note,
+		CreatedBy:      c.GetString("username"),
+	}
+	err = tx.QueryRow(
+		`INSERT INTO stock_transfers (from_location_id, to_location_id, note, created_by)
+         VALUES ($1, $2, $3, $4) RETURNING id, created_at`,
+		t.FromLocationID, t.ToLocationID, t.Note, t.CreatedBy,
+	).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка создания перемещения",
+		})
+		return
+	}
+
+	for _, warehouseID := range warehouseIDs {
+		quantity := quantities[warehouseID]
+
+		_, err = tx.Exec(
+			"INSERT INTO stock_transfer_items (transfer_id, warehouse_id, quantity) VALUES ($1, $2, $3)",
+			t.ID, warehouseID, quantity,
+		)
+		// Партии перемещаются по FEFO вместе с товаром, включая просроченные
+		var allocations []lotAllocation
+		if err == nil {
+			allocations, err = allocateLots(tx, warehouseID, req.FromLocationID, quantity, true)
+		}
+		if err == nil {
+			err = moveLots(tx, allocations, req.ToLocationID)
+		}
+		if err == nil {
+			err = adjustStock(tx, warehouseID, req.FromLocationID, -quantity)
+		}
+		if err == nil {
+			err = adjustStock(tx, warehouseID, req.ToLocationID, quantity)
+		}
+		if err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка перемещения товара",
+			})
+			return
+		}
+
+		t.Items = append(t.Items, models.StockTransferItem{WarehouseID: warehouseID, Quantity: quantity})
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка коммита транзакции",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    t,
+		Message: "Перемещение успешно проведено",
+	})
+}
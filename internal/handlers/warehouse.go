@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"store_app/internal/models"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,13 +19,79 @@ func NewWarehousesHandler(db *sql.DB) *WarehousesHandler {
 	return &WarehousesHandler{DB: db}
 }
 
-// GetWarehouses возвращает все товары
+const warehouseProductColumns = `id, name, COALESCE(sku, ''), COALESCE(barcode, ''), category_id, unit, description, is_active`
+
+const warehouseColumns = warehouseProductColumns + `, quantity, amount, tax_rate, reorder_point, reorder_quantity`
+
+func scanWarehouse(row interface{ Scan(...interface{}) error }, w *models.Warehouse) error {
+	return row.Scan(&w.ID, &w.Name, &w.SKU, &w.Barcode, &w.CategoryID, &w.Unit, &w.Description, &w.IsActive,
+		&w.Quantity, &w.Amount, &w.TaxRate, &w.ReorderPoint, &w.ReorderQuantity)
+}
+
+// warehouseRequest - данные товара при создании и обновлении.
+// Поля, не переданные при обновлении, сохраняют текущее значение,
+// пустая строка в sku или barcode очищает значение.
+type warehouseRequest struct {
+	Name        *string  `json:"name"`
+	SKU         *string  `json:"sku" binding:"omitempty,max=64"`
+	Barcode     *string  `json:"barcode" binding:"omitempty,max=64"`
+	CategoryID  *int     `json:"category_id"`
+	Unit        *string  `json:"unit" binding:"omitempty,max=16"`
+	Description *string  `json:"description"`
+	IsActive    *bool    `json:"is_active"`
+	Quantity    *int     `json:"quantity"`
+	Amount      *float64 `json:"amount"`
+	TaxRate     *float64 `json:"tax_rate" binding:"omitempty,min=0,lt=100"`
+
+	ReorderPoint    *int `json:"reorder_point" binding:"omitempty,min=0"`
+	ReorderQuantity *int `json:"reorder_quantity" binding:"omitempty,min=0"`
+}
+
+// setCreateDefaults подставляет при создании товара пустое название и нулевые количество и цену,
+// если они не переданы
+func (r *warehouseRequest) setCreateDefaults() {
+	if r.Name == nil {
+		r.Name = new(string)
+	}
+	if r.Quantity == nil {
+		r.Quantity = new(int)
+	}
+	if r.Amount == nil {
+		r.Amount = new(float64)
+	}
+}
+
+// GetWarehouses возвращает все товары.
+// Фильтры: category_id (с подкатегориями), active, search (название, SKU или штрихкод).
+// С параметром location_id количество - остаток на указанной локации.
 func (h *WarehousesHandler) GetWarehouses(c *gin.Context) {
+	categoryID, ok := queryInt(c, "category_id")
+	if !ok {
+		return
+	}
+	locationID, ok := queryInt(c, "location_id")
+	if !ok {
+		return
+	}
+	active, ok := queryBool(c, "active")
+	if !ok {
+		return
+	}
+	search := c.Query("search")
+
 	rows, err := h.DB.Query(`
-        SELECT id, name, quantity, amount 
+        SELECT `+warehouseProductColumns+`,
+               CASE WHEN $4::int IS NULL THEN quantity
+                    ELSE COALESCE((SELECT sl.quantity FROM stock_levels sl
+                                   WHERE sl.warehouse_id = warehouses.id AND sl.location_id = $4), 0)
+               END,
+               amount, tax_rate, reorder_point, reorder_quantity
         FROM warehouses 
+        WHERE `+categoryFilter("category_id", 1)+`
+          AND ($2::boolean IS NULL OR is_active = $2)
+          AND ($3 = '' OR name ILIKE '%' || $3 || '%' OR sku = $3 OR barcode = $3)
         ORDER BY id
-    `)
+    `, categoryID, active, search, locationID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
@@ -37,7 +104,7 @@ func (h *WarehousesHandler) GetWarehouses(c *gin.Context) {
 	var warehouses []models.Warehouse
 	for rows.Next() {
 		var w models.Warehouse
-		if err := rows.Scan(&w.ID, &w.Name, &w.Quantity, &w.Amount); err != nil {
+		if err := scanWarehouse(rows, &w); err != nil {
 			continue
 		}
 		warehouses = append(warehouses, w)
@@ -49,7 +116,7 @@ func (h *WarehousesHandler) GetWarehouses(c *gin.Context) {
 	})
 }
 
-// GetWarehouse возвращает товар по ID
+// GetWarehouse возвращает товар по ID с фактическим, зарезервированным и доступным количеством
 func (h *WarehousesHandler) GetWarehouse(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -61,10 +128,19 @@ func (h *WarehousesHandler) GetWarehouse(c *gin.Context) {
 	}
 
 	var warehouse models.Warehouse
-	err = h.DB.QueryRow(
-		"SELECT id, name, quantity, amount FROM warehouses WHERE id = $1",
+	err = scanWarehouse(h.DB.QueryRow(
+		"SELECT "+warehouseColumns+" FROM warehouses WHERE id = $1",
 		id,
-	).Scan(&warehouse.ID, &warehouse.Name, &warehouse.Quantity, &warehouse.Amount)
+	), &warehouse)
+
+	var reserved int
+	if err == nil {
+		err = h.DB.QueryRow(`
+            SELECT COALESCE(SUM(quantity), 0)
+            FROM reservations
+            WHERE warehouse_id = $1 AND status = 'active' AND expires_at > CURRENT_TIMESTAMP
+        `, id).Scan(&reserved)
+	}
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -81,38 +157,134 @@ func (h *WarehousesHandler) GetWarehouse(c *gin.Context) {
 		return
 	}
 
+	available := warehouse.Quantity - reserved
+	warehouse.Reserved = &reserved
+	warehouse.Available = &available
+
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    warehouse,
 	})
 }
 
-// CreateWarehouse создает новый товар
-func (h *WarehousesHandler) CreateWarehouse(c *gin.Context) {
+// GetWarehouseByBarcode возвращает товар по штрихкоду (для сканеров)
+func (h *WarehousesHandler) GetWarehouseByBarcode(c *gin.Context) {
 	var warehouse models.Warehouse
-	if err := c.ShouldBindJSON(&warehouse); err != nil {
+	err := scanWarehouse(h.DB.QueryRow(
+		"SELECT "+warehouseColumns+" FROM warehouses WHERE barcode = $1",
+		c.Param("code"),
+	), &warehouse)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Товар не найден",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка получения товара",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    warehouse,
+	})
+}
+
+// CreateWarehouse создает новый товар, начальный остаток приходуется на основную локацию
+func (h *WarehousesHandler) CreateWarehouse(c *gin.Context) {
+	var req warehouseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
 			Error:   "Неверный формат данных",
 		})
 		return
 	}
+	req.setCreateDefaults()
+
+	if *req.Quantity < 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Количество не может быть отрицательным",
+		})
+		return
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка начала транзакции",
+		})
+		return
+	}
 
 	var id int
-	err := h.DB.QueryRow(
-		"INSERT INTO warehouses (name, quantity, amount) VALUES ($1, $2, $3) RETURNING id",
-		warehouse.Name, warehouse.Quantity, warehouse.Amount,
+	err = tx.QueryRow(
+		`INSERT INTO warehouses (name, sku, barcode, category_id, unit, description, is_active, quantity, amount, tax_rate,
+                                 reorder_point, reorder_quantity)
+         VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, COALESCE($5, 'шт'), COALESCE($6, ''), COALESCE($7, true), 0, $8, COALESCE($9, 0),
+                 COALESCE($10, 0), COALESCE($11, 0))
+         RETURNING id`,
+		req.Name, req.SKU, req.Barcode, req.CategoryID, req.Unit, req.Description, req.IsActive,
+		req.Amount, req.TaxRate, req.ReorderPoint, req.ReorderQuantity,
 	).Scan(&id)
 
+	if err == nil {
+		err = recordPrice(tx, id, *req.Amount, c.GetString("username"))
+	}
+
+	if err == nil && *req.Quantity > 0 {
+		if apiErr := ensurePeriodOpen(tx, time.Now()); apiErr != nil {
+			tx.Rollback()
+			c.JSON(apiErr.Status, models.APIResponse{
+				Success: false,
+				Error:   apiErr.Message,
+			})
+			return
+		}
+
+		var locationID int
+		if locationID, err = defaultLocationID(tx); err == nil {
+			err = adjustStock(tx, id, locationID, *req.Quantity)
+		}
+	}
+
+	var warehouse models.Warehouse
+	if err == nil {
+		err = scanWarehouse(tx.QueryRow("SELECT "+warehouseColumns+" FROM warehouses WHERE id = $1", id), &warehouse)
+	}
+
 	if err != nil {
+		tx.Rollback()
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Error:   "Товар с таким SKU или штрихкодом уже существует",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка создания товара",
+			})
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   "Ошибка создания товара",
+			Error:   "Ошибка коммита транзакции",
 		})
 		return
 	}
 
-	warehouse.ID = id
 	c.JSON(http.StatusCreated, models.APIResponse{
 		Success: true,
 		Data:    warehouse,
@@ -120,7 +292,8 @@ func (h *WarehousesHandler) CreateWarehouse(c *gin.Context) {
 	})
 }
 
-// UpdateWarehouse обновляет товар
+// UpdateWarehouse обновляет переданные поля товара. Изменение общего количества применяется
+// к остатку на основной локации, остатки на других локациях меняются перемещениями.
 func (h *WarehousesHandler) UpdateWarehouse(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -131,8 +304,8 @@ func (h *WarehousesHandler) UpdateWarehouse(c *gin.Context) {
 		return
 	}
 
-	var warehouse models.Warehouse
-	if err := c.ShouldBindJSON(&warehouse); err != nil {
+	var req warehouseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
 			Error:   "Неверный формат данных",
@@ -140,28 +313,104 @@ func (h *WarehousesHandler) UpdateWarehouse(c *gin.Context) {
 		return
 	}
 
-	result, err := h.DB.Exec(
-		"UPDATE warehouses SET name = $1, quantity = $2, amount = $3 WHERE id = $4",
-		warehouse.Name, warehouse.Quantity, warehouse.Amount, id,
-	)
+	tx, err := h.DB.Begin()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   "Ошибка обновления товара",
+			Error:   "Ошибка начала транзакции",
 		})
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		c.JSON(http.StatusNotFound, models.APIResponse{
+	// Остаток на основной локации блокируется раньше товара, как при продаже и перемещении:
+	// UPDATE ниже блокирует строку товара, иначе возможна взаимоблокировка
+	locationID, err := defaultLocationID(tx)
+	if err == nil {
+		err = lockStockRows(tx, []int{id}, locationID)
+	}
+
+	// Прежняя цена нужна, чтобы записать изменение в историю цен
+	var currentQuantity int
+	var previousAmount float64
+	if err == nil {
+		err = tx.QueryRow(
+			`WITH previous AS (SELECT amount FROM warehouses WHERE id = $12 FOR UPDATE)
+         UPDATE warehouses
+         SET name = COALESCE($1, name), sku = NULLIF(COALESCE($2, sku), ''), barcode = NULLIF(COALESCE($3, barcode), ''),
+             category_id = COALESCE($4, category_id), unit = COALESCE($5, unit),
+             description = COALESCE($6, description), is_active = COALESCE($7, is_active),
+             amount = COALESCE($8, amount), tax_rate = COALESCE($9, tax_rate),
+             reorder_point = COALESCE($10, reorder_point), reorder_quantity = COALESCE($11, reorder_quantity)
+         WHERE id = $12
+         RETURNING quantity, COALESCE((SELECT amount FROM previous), 0)`,
+			req.Name, req.SKU, req.Barcode, req.CategoryID, req.Unit, req.Description, req.IsActive,
+			req.Amount, req.TaxRate, req.ReorderPoint, req.ReorderQuantity, id,
+		).Scan(&currentQuantity, &previousAmount)
+	}
+
+	if err == nil && req.Amount != nil && *req.Amount != previousAmount {
+		err = recordPrice(tx, id, *req.Amount, c.GetString("username"))
+	}
+
+	if err == nil && req.Quantity != nil && *req.Quantity != currentQuantity {
+		if apiErr := ensurePeriodOpen(tx, time.Now()); apiErr != nil {
+			tx.Rollback()
+			c.JSON(apiErr.Status, models.APIResponse{
+				Success: false,
+				Error:   apiErr.Message,
+			})
+			return
+		}
+
+		var defaultQuantity int
+		defaultQuantity, err = lockStock(tx, id, locationID)
+		if err == nil && defaultQuantity+*req.Quantity-currentQuantity < 0 {
+			tx.Rollback()
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Error:   "Недостаточно товара на основной локации, используйте перемещение между локациями",
+			})
+			return
+		}
+		if err == nil {
+			err = adjustStock(tx, id, locationID, *req.Quantity-currentQuantity)
+		}
+	}
+
+	var warehouse models.Warehouse
+	if err == nil {
+		err = scanWarehouse(tx.QueryRow("SELECT "+warehouseColumns+" FROM warehouses WHERE id = $1", id), &warehouse)
+	}
+
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Error:   "Товар не найден",
+			})
+		} else if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, models.APIResponse{
+				Success: false,
+				Error:   "Товар с таким SKU или штрихкодом уже существует",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, models.APIResponse{
+				Success: false,
+				Error:   "Ошибка обновления товара",
+			})
+		}
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
 			Success: false,
-			Error:   "Товар не найден",
+			Error:   "Ошибка коммита транзакции",
 		})
 		return
 	}
 
-	warehouse.ID = id
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
 		Data:    warehouse,
@@ -203,3 +452,46 @@ func (h *WarehousesHandler) DeleteWarehouse(c *gin.Context) {
 		Message: "Товар успешно удален",
 	})
 }
+
+// GetWarehouseStock возвращает остатки товара в разрезе локаций
+func (h *WarehousesHandler) GetWarehouseStock(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Error:   "Неверный ID товара",
+		})
+		return
+	}
+
+	rows, err := h.DB.Query(`
+        SELECT `+stockLevelColumns+`
+        FROM stock_levels sl
+        JOIN warehouses w ON w.id = sl.warehouse_id
+        JOIN locations l ON l.id = sl.location_id
+        WHERE sl.warehouse_id = $1
+        ORDER BY l.id
+    `, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   "Ошибка получения остатков",
+		})
+		return
+	}
+	defer rows.Close()
+
+	var stock []models.StockLevel
+	for rows.Next() {
+		var sl models.StockLevel
+		if err := scanStockLevel(rows, &sl); err != nil {
+			continue
+		}
+		stock = append(stock, sl)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    stock,
+	})
+}
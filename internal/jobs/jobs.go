@@ -0,0 +1,54 @@
+// jobs/jobs.go
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"store_app/internal/config"
+	"store_app/internal/notify"
+	"time"
+)
+
+// Job - фоновая задача, выполняемая с заданным интервалом.
+// Задачи должны быть идемпотентны: API может работать в нескольких репликах,
+// и одна и та же задача запускается в каждой из них.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(db *sql.DB) error
+}
+
+// Start запускает фоновые задачи приложения до отмены ctx
+func Start(ctx context.Context, db *sql.DB, cfg *config.Config) {
+	notifier := notify.New(db, cfg.Alerts)
+	createRecurringCharges := func(db *sql.DB) error { return CreateRecurringCharges(db, notifier) }
+
+	jobs := []Job{
+		{Name: "release-expired-reservations", Interval: cfg.Reservations.SweepInterval, Run: ReleaseExpiredReservations},
+		{Name: "apply-scheduled-prices", Interval: cfg.Prices.SweepInterval, Run: ApplyScheduledPrices},
+		{Name: "create-recurring-charges", Interval: cfg.Charges.RecurringInterval, Run: createRecurringCharges},
+	}
+
+	for _, job := range jobs {
+		go run(ctx, db, job)
+	}
+}
+
+// run выполняет задачу сразу и затем с интервалом, ошибки пишутся в лог
+func run(ctx context.Context, db *sql.DB, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job.Run(db); err != nil {
+			log.Printf("Job %s failed: %v", job.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
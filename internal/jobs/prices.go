@@ -0,0 +1,30 @@
+// jobs/prices.go
+package jobs
+
+import (
+	"database/sql"
+	"log"
+)
+
+// ApplyScheduledPrices переносит в warehouses.amount цены из истории, вступившие в силу.
+// Продажи берут цену на момент продажи из истории и не зависят от задержки этой задачи.
+func ApplyScheduledPrices(db *sql.DB) error {
+	result, err := db.Exec(`
+        UPDATE warehouses w SET amount = p.amount
+        FROM (
+            SELECT DISTINCT ON (warehouse_id) warehouse_id, amount
+            FROM price_history
+            WHERE effective_at <= CURRENT_TIMESTAMP
+            ORDER BY warehouse_id, effective_at DESC, id DESC
+        ) p
+        WHERE w.id = p.warehouse_id AND w.amount IS DISTINCT FROM p.amount
+    `)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := result.RowsAffected(); n > 0 {
+		log.Printf("Applied scheduled prices to %d products", n)
+	}
+	return nil
+}
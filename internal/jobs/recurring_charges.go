@@ -0,0 +1,144 @@
+// jobs/recurring_charges.go
+package jobs
+
+import (
+	"database/sql"
+	"log"
+	"store_app/internal/handlers"
+	"store_app/internal/models"
+	"store_app/internal/notify"
+	"store_app/internal/recurrence"
+	"time"
+)
+
+type recurringCharge struct {
+	id            int
+	name          string
+	expenseItemID int
+	supplierID    *int
+	amount        float64
+	schedule      recurrence.Schedule
+	createdAt     time.Time
+}
+
+// CreateRecurringCharges создает расходы по активным шаблонам за наступившие даты.
+// Пропущенные даты (например, пока API не работало) догоняются, но не раньше создания
+// шаблона. Даты в закрытых учетных периодах пропускаются. Расходы создаются по правилам API:
+// сумма выше порога утверждения статьи требует утверждения, бюджет с жестким лимитом не дает
+// создать расход - такая дата повторяется при следующих запусках, пока бюджет не увеличат.
+func CreateRecurringCharges(db *sql.DB, notifier notify.Notifier) error {
+	rows, err := db.Query(`
+        SELECT id, name, expense_item_id, supplier_id, amount, frequency,
+               COALESCE(day_of_month, 0), COALESCE(day_of_week, 0), starts_on, ends_on, created_at
+        FROM recurring_charges
+        WHERE is_active
+    `)
+	if err != nil {
+		return err
+	}
+
+	var templates []recurringCharge
+	for rows.Next() {
+		var t recurringCharge
+		if err := rows.Scan(&t.id, &t.name, &t.expenseItemID, &t.supplierID, &t.amount, &t.schedule.Frequency,
+			&t.schedule.DayOfMonth, &t.schedule.DayOfWeek, &t.schedule.StartsOn, &t.schedule.EndsOn,
+			&t.createdAt); err != nil {
+			rows.Close()
+			return err
+		}
+		templates = append(templates, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	today := recurrence.Date(time.Now())
+
+	created := 0
+	for _, t := range templates {
+		for _, date := range t.schedule.Between(recurrence.Date(t.createdAt), today) {
+			ok, err := createRecurringCharge(db, notifier, t, date)
+			if err != nil {
+				// Ошибка одной даты (например, превышение максимальной суммы или жесткого лимита
+				// бюджета) не останавливает остальные
+				log.Printf("Recurring charge %d for %s failed: %v", t.id, date.Format("2006-01-02"), err)
+				continue
+			}
+			if ok {
+				created++
+			}
+		}
+	}
+
+	if created > 0 {
+		log.Printf("Created %d recurring charges", created)
+	}
+	return nil
+}
+
+// createRecurringCharge создает расход шаблона за дату, если он еще не создан.
+// Запись о запуске вставляется первой: вторая реплика дождется коммита и пропустит дату.
+// Дата в закрытом учетном периоде пропускается без записи о запуске и будет создана,
+// если период откроют.
+func createRecurringCharge(db *sql.DB, notifier notify.Notifier, t recurringCharge, date time.Time) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Та же блокировка периода, что и у изменений через API: закрытие дождется коммита
+	year, month := date.Year(), int(date.Month())
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock_shared(hashtext('accounting_periods'), $1)", year*100+month); err != nil {
+		return false, err
+	}
+
+	var closed bool
+	err = tx.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM accounting_periods WHERE year = $1 AND month = $2 AND status = 'closed')",
+		year, month,
+	).Scan(&closed)
+	if err != nil || closed {
+		return false, err
+	}
+
+	result, err := tx.Exec(`
+        INSERT INTO recurring_charge_runs (template_id, occurrence_date) VALUES ($1, $2)
+        ON CONFLICT (template_id, occurrence_date) DO NOTHING
+    `, t.id, date)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	charge, events, err := handlers.InsertCharge(tx, models.Charge{
+		ExpenseItemID: t.expenseItemID,
+		SupplierID:    t.supplierID,
+		Amount:        t.amount,
+		ChargeDate:    date,
+		Description:   t.name,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(
+		"UPDATE recurring_charge_runs SET charge_id = $1 WHERE template_id = $2 AND occurrence_date = $3",
+		charge.ID, t.id, date,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	for _, e := range events {
+		notify.Send(notifier, e)
+	}
+	return true, nil
+}
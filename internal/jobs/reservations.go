@@ -0,0 +1,24 @@
+// jobs/reservations.go
+package jobs
+
+import (
+	"database/sql"
+	"log"
+)
+
+// ReleaseExpiredReservations переводит просроченные резервы в статус expired.
+// Доступный остаток учитывает срок резерва и без этой задачи, она закрывает резервы в журнале.
+func ReleaseExpiredReservations(db *sql.DB) error {
+	result, err := db.Exec(`
+        UPDATE reservations SET status = 'expired'
+        WHERE status = 'active' AND expires_at <= CURRENT_TIMESTAMP
+    `)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := result.RowsAffected(); n > 0 {
+		log.Printf("Released %d expired reservations", n)
+	}
+	return nil
+}
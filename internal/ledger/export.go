@@ -0,0 +1,65 @@
+// ledger/export.go
+package ledger
+
+import (
+	"encoding/csv"
+	"io"
+	"store_app/internal/models"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// sourceNames - названия документов для выгрузки в 1С
+var sourceNames = map[string]string{
+	SourceSale:     "Продажа",
+	SourceRefund:   "Возврат",
+	SourcePurchase: "Поступление товаров",
+	SourceCharge:   "Расход",
+}
+
+// WriteCSV выгружает проводки в CSV: UTF-8, разделитель - запятая, первая строка - заголовок
+func WriteCSV(w io.Writer, entries []models.JournalEntry) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "date", "debit", "credit", "amount", "source_type", "source_id", "description"})
+	for _, e := range entries {
+		cw.Write([]string{
+			strconv.Itoa(e.ID),
+			e.EntryDate.Format("2006-01-02"),
+			e.DebitAccount,
+			e.CreditAccount,
+			strconv.FormatFloat(e.Amount, 'f', 2, 64),
+			e.SourceType,
+			strconv.Itoa(e.SourceID),
+			e.Description,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Write1C выгружает проводки для загрузки в 1С из табличного документа: кодировка Windows-1251,
+// разделитель - точка с запятой, строки через CRLF, дата ДД.ММ.ГГГГ, дробная часть суммы через запятую.
+// Символы, которых нет в Windows-1251, заменяются.
+func Write1C(w io.Writer, entries []models.JournalEntry) error {
+	ew := encoding.ReplaceUnsupported(charmap.Windows1251.NewEncoder()).Writer(w)
+	cw := csv.NewWriter(ew)
+	cw.Comma = ';'
+	cw.UseCRLF = true
+
+	cw.Write([]string{"Дата", "СчетДт", "СчетКт", "Сумма", "Содержание", "Документ"})
+	for _, e := range entries {
+		cw.Write([]string{
+			e.EntryDate.Format("02.01.2006"),
+			e.DebitAccount,
+			e.CreditAccount,
+			strings.Replace(strconv.FormatFloat(e.Amount, 'f', 2, 64), ".", ",", 1),
+			e.Description,
+			sourceNames[e.SourceType] + " " + strconv.Itoa(e.SourceID),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,194 @@
+// Package ledger делает бухгалтерские проводки по документам магазина
+package ledger
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Счета плана счетов, на которые проводки делаются всегда. Счета денег по способам
+// оплаты и счета затрат статей расходов настраиваются и хранятся в БД.
+const (
+	AccountGoods           = "41"
+	AccountSellingExpenses = "44"
+	AccountSuppliers       = "60"
+	AccountTaxes           = "68"
+	AccountOtherCreditors  = "76"
+	AccountRevenue         = "90.01"
+	AccountVAT             = "90.03"
+)
+
+// Документы, по которым делаются проводки
+const (
+	SourceSale     = "sale"
+	SourceRefund   = "refund"
+	SourcePurchase = "purchase"
+	SourceCharge   = "charge"
+)
+
+// Queryer - общий интерфейс *sql.DB и *sql.Tx
+type Queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// entry - проводка: дебет и кредит счетов (по коду) на сумму
+type entry struct {
+	debit       string
+	credit      string
+	amount      float64
+	description string
+}
+
+// post записывает проводки документа датой date. Проводки с нулевой суммой пропускаются.
+func post(q Queryer, sourceType string, sourceID int, date time.Time, entries []entry) error {
+	for _, e := range entries {
+		amount := math.Round(e.amount*100) / 100
+		if amount <= 0 {
+			continue
+		}
+
+		result, err := q.Exec(`
+            INSERT INTO journal_entries (entry_date, debit_account_id, credit_account_id, amount,
+                                         source_type, source_id, description)
+            SELECT $1, d.id, c.id, $4, $5, $6, $7
+            FROM accounts d, accounts c
+            WHERE d.code = $2 AND c.code = $3
+        `, date, e.debit, e.credit, amount, sourceType, sourceID, e.description)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return fmt.Errorf("ledger: account %s or %s not found", e.debit, e.credit)
+		}
+	}
+	return nil
+}
+
+// PostSale проводит продажу: поступление денег по каждому способу оплаты в выручку (Дт счет
+// способа оплаты Кт 90.01) и начисление налога с выручки (Дт 90.03 Кт 68).
+// Вызывается в транзакции оформления продажи после записи оплат.
+func PostSale(q Queryer, saleID int) error {
+	var saleDate time.Time
+	var taxAmount float64
+	err := q.QueryRow("SELECT sale_date, tax_amount FROM sales WHERE id = $1", saleID).Scan(&saleDate, &taxAmount)
+	if err != nil {
+		return err
+	}
+
+	rows, err := q.Query(`
+        SELECT p.method, a.code, SUM(p.amount)
+        FROM sale_payments p
+        LEFT JOIN payment_method_accounts m ON m.method = p.method
+        LEFT JOIN accounts a ON a.id = m.account_id
+        WHERE p.sale_id = $1
+        GROUP BY p.method, a.code
+        ORDER BY p.method
+    `, saleID)
+	if err != nil {
+		return err
+	}
+
+	var entries []entry
+	for rows.Next() {
+		var method string
+		var account *string
+		var amount float64
+		if err := rows.Scan(&method, &account, &amount); err != nil {
+			rows.Close()
+			return err
+		}
+		if account == nil {
+			rows.Close()
+			return fmt.Errorf("ledger: no account for payment method %s", method)
+		}
+		entries = append(entries, entry{*account, AccountRevenue, amount, fmt.Sprintf("Продажа №%d", saleID)})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	entries = append(entries, entry{AccountVAT, AccountTaxes, taxAmount, fmt.Sprintf("НДС с продажи №%d", saleID)})
+	return post(q, SourceSale, saleID, saleDate, entries)
+}
+
+// PostRefund сторнирует проводки продажи обратными проводками датой date.
+// Вызывается до удаления продажи, проводки самой продажи остаются в журнале.
+func PostRefund(q Queryer, saleID int, date time.Time) error {
+	_, err := q.Exec(`
+        INSERT INTO journal_entries (entry_date, debit_account_id, credit_account_id, amount,
+                                     source_type, source_id, description)
+        SELECT $2, credit_account_id, debit_account_id, amount, $3, source_id, 'Возврат: ' || description
+        FROM journal_entries
+        WHERE source_type = $4 AND source_id = $1
+        ORDER BY id
+    `, saleID, date, SourceRefund, SourceSale)
+	return err
+}
+
+// PostPurchase проводит поступление товара от поставщика по закупочной стоимости
+// (Дт 41 Кт 60). Повторные приемки в ту же партию проводятся отдельно.
+func PostPurchase(q Queryer, lotID int, cost float64, date time.Time, description string) error {
+	return post(q, SourcePurchase, lotID, date, []entry{{AccountGoods, AccountSuppliers, cost, description}})
+}
+
+// RepostCharge перепроводит расход: удаляет его проводки и, если расход существует и утвержден,
+// проводит заново (Дт счет затрат статьи Кт 60 при поставщике, иначе Кт 76). Счет затрат -
+// счет статьи или ближайшей родительской статьи, по умолчанию 44. Вызывается после любого
+// изменения расхода в той же транзакции.
+func RepostCharge(q Queryer, chargeID int) error {
+	_, err := q.Exec("DELETE FROM journal_entries WHERE source_type = $1 AND source_id = $2", SourceCharge, chargeID)
+	if err != nil {
+		return err
+	}
+
+	var chargeDate time.Time
+	var amount float64
+	var status, itemName, documentNumber string
+	var supplierID *int
+	var account *string
+	err = q.QueryRow(`
+        WITH RECURSIVE ancestors AS (
+            SELECT e.id, e.parent_id, e.account_id, 0 as depth
+            FROM expense_items e JOIN charges c ON c.expense_item_id = e.id
+            WHERE c.id = $1
+            UNION ALL
+            SELECT e.id, e.parent_id, e.account_id, a.depth + 1
+            FROM expense_items e JOIN ancestors a ON e.id = a.parent_id
+        )
+        SELECT c.charge_date, c.amount, c.status, c.supplier_id, e.name, c.document_number,
+               (SELECT acc.code FROM ancestors a JOIN accounts acc ON acc.id = a.account_id
+                ORDER BY a.depth LIMIT 1)
+        FROM charges c
+        JOIN expense_items e ON e.id = c.expense_item_id
+        WHERE c.id = $1
+    `, chargeID).Scan(&chargeDate, &amount, &status, &supplierID, &itemName, &documentNumber, &account)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if status != "approved" {
+		return nil
+	}
+
+	debit := AccountSellingExpenses
+	if account != nil {
+		debit = *account
+	}
+	credit := AccountOtherCreditors
+	if supplierID != nil {
+		credit = AccountSuppliers
+	}
+
+	description := fmt.Sprintf("Расход №%d: %s", chargeID, itemName)
+	if documentNumber != "" {
+		description += ", документ " + documentNumber
+	}
+	return post(q, SourceCharge, chargeID, chargeDate, []entry{{debit, credit, amount, description}})
+}
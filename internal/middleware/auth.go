@@ -62,3 +62,19 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireRole пропускает запрос только для пользователей с указанной ролью.
+// Должен подключаться после AuthMiddleware.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != role {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "Недостаточно прав",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -2,6 +2,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -13,30 +14,524 @@ type User struct {
 }
 
 type Warehouse struct {
-	ID       int     `json:"id"`
-	Name     string  `json:"name"`
-	Quantity int     `json:"quantity"`
-	Amount   float64 `json:"amount"`
+	ID              int     `json:"id"`
+	Name            string  `json:"name"`
+	SKU             string  `json:"sku"`
+	Barcode         string  `json:"barcode"`
+	CategoryID      *int    `json:"category_id"`
+	Unit            string  `json:"unit"`
+	Description     string  `json:"description"`
+	IsActive        bool    `json:"is_active"`
+	Quantity        int     `json:"quantity"`
+	Amount          float64 `json:"amount"`
+	TaxRate         float64 `json:"tax_rate"`
+	ReorderPoint    int     `json:"reorder_point"`
+	ReorderQuantity int     `json:"reorder_quantity"`
+	Reserved        *int    `json:"reserved,omitempty"`
+	Available       *int    `json:"available,omitempty"`
+}
+
+// Category - категория товаров, ParentID пустой у корневых категорий
+type Category struct {
+	ID       int         `json:"id"`
+	Name     string      `json:"name"`
+	ParentID *int        `json:"parent_id"`
+	Children []*Category `json:"children,omitempty"`
 }
 
 type Sale struct {
+	ID             int           `json:"id"`
+	WarehouseID    int           `json:"warehouse_id"`
+	CustomerID     *int          `json:"customer_id"`
+	LocationID     *int          `json:"location_id"`
+	PromotionID    *int          `json:"promotion_id"`
+	Quantity       int           `json:"quantity"`
+	Subtotal       float64       `json:"subtotal"`
+	DiscountAmount float64       `json:"discount_amount"`
+	TaxRate        float64       `json:"tax_rate"`
+	NetAmount      float64       `json:"net_amount"`
+	TaxAmount      float64       `json:"tax_amount"`
+	Amount         float64       `json:"amount"`
+	Cashier        string        `json:"cashier"`
+	SaleDate       time.Time     `json:"sale_date"`
+	Payments       []SalePayment `json:"payments,omitempty"`
+	Lots           []SaleLot     `json:"lots,omitempty"`
+}
+
+// SalePayment - оплата продажи одним способом: cash, card, transfer или gift_card
+type SalePayment struct {
+	Method string  `json:"method"`
+	Amount float64 `json:"amount"`
+}
+
+// Promotion описывает правило автоматической скидки.
+// Kind: percent - процент от суммы, fixed - сумма скидки на единицу товара,
+// buy_x_get_y - при покупке BuyQuantity единиц еще FreeQuantity бесплатно.
+// Акция ограничивается товаром (WarehouseID) и/или категорией с подкатегориями (CategoryID),
+// если оба поля пустые - действует на все товары.
+type Promotion struct {
+	ID           int        `json:"id"`
+	Name         string     `json:"name"`
+	Kind         string     `json:"kind"`
+	Value        float64    `json:"value"`
+	BuyQuantity  *int       `json:"buy_quantity"`
+	FreeQuantity *int       `json:"free_quantity"`
+	WarehouseID  *int       `json:"warehouse_id"`
+	CategoryID   *int       `json:"category_id"`
+	StartsAt     time.Time  `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+	IsActive     bool       `json:"is_active"`
+}
+
+type Customer struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Phone     string    `json:"phone"`
+	Email     string    `json:"email"`
+	Notes     string    `json:"notes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Supplier - поставщик товаров. PaymentTermsDays - отсрочка оплаты в днях.
+type Supplier struct {
+	ID               int       `json:"id"`
+	Name             string    `json:"name"`
+	ContactName      string    `json:"contact_name"`
+	Phone            string    `json:"phone"`
+	Email            string    `json:"email"`
+	PaymentTermsDays int       `json:"payment_terms_days"`
+	LeadTimeDays     *int      `json:"lead_time_days"`
+	Notes            string    `json:"notes"`
+	IsActive         bool      `json:"is_active"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// ProductSupplier - товар у поставщика: артикул поставщика и последняя закупочная цена
+type ProductSupplier struct {
+	WarehouseID       int        `json:"warehouse_id"`
+	ProductName       string     `json:"product_name"`
+	SupplierID        int        `json:"supplier_id"`
+	SupplierName      string     `json:"supplier_name"`
+	SupplierSKU       string     `json:"supplier_sku"`
+	LastPurchasePrice *float64   `json:"last_purchase_price"`
+	LastPurchasedAt   *time.Time `json:"last_purchased_at"`
+	IsPrimary         bool       `json:"is_primary"`
+}
+
+// CustomerStats содержит сводку по покупкам клиента
+type CustomerStats struct {
+	CustomerID     int        `json:"customer_id"`
+	PurchasesCount int        `json:"purchases_count"`
+	ItemsCount     int        `json:"items_count"`
+	LifetimeValue  float64    `json:"lifetime_value"`
+	AverageTicket  float64    `json:"average_ticket"`
+	FirstPurchase  *time.Time `json:"first_purchase"`
+	LastPurchase   *time.Time `json:"last_purchase"`
+}
+
+// Location - физическое место хранения и продажи товара (торговый зал, подсобка, склад)
+type Location struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	IsDefault bool   `json:"is_default"`
+	IsActive  bool   `json:"is_active"`
+}
+
+// PriceChange - цена товара, действующая с EffectiveAt. Scheduled - изменение запланировано и еще не вступило в силу.
+type PriceChange struct {
 	ID          int       `json:"id"`
 	WarehouseID int       `json:"warehouse_id"`
-	Quantity    int       `json:"quantity"`
 	Amount      float64   `json:"amount"`
-	SaleDate    time.Time `json:"sale_date"`
+	EffectiveAt time.Time `json:"effective_at"`
+	Scheduled   bool      `json:"scheduled"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// StockLevel - остаток товара на локации
+type StockLevel struct {
+	WarehouseID  int    `json:"warehouse_id"`
+	ProductName  string `json:"product_name,omitempty"`
+	LocationID   int    `json:"location_id"`
+	LocationName string `json:"location_name,omitempty"`
+	Quantity     int    `json:"quantity"`
+	Reserved     int    `json:"reserved"`
+	Available    int    `json:"available"`
+}
+
+// Lot - партия товара на локации с номером и сроком годности
+type Lot struct {
+	ID               int        `json:"id"`
+	WarehouseID      int        `json:"warehouse_id"`
+	ProductName      string     `json:"product_name,omitempty"`
+	LocationID       int        `json:"location_id"`
+	LotNumber        string     `json:"lot_number"`
+	ExpiryDate       *time.Time `json:"expiry_date"`
+	Quantity         int        `json:"quantity"`
+	ReceivedQuantity int        `json:"received_quantity"`
+	ReceivedAt       time.Time  `json:"received_at"`
+	Expired          bool       `json:"expired"`
+}
+
+// SaleLot - количество товара продажи, списанное из партии
+type SaleLot struct {
+	LotID      int        `json:"lot_id"`
+	LotNumber  string     `json:"lot_number"`
+	ExpiryDate *time.Time `json:"expiry_date"`
+	Quantity   int        `json:"quantity"`
+}
+
+// StockTransfer - документ перемещения товара между локациями
+type StockTransfer struct {
+	ID             int                 `json:"id"`
+	FromLocationID int                 `json:"from_location_id"`
+	ToLocationID   int                 `json:"to_location_id"`
+	Note           string              `json:"note"`
+	CreatedBy      string              `json:"created_by"`
+	CreatedAt      time.Time           `json:"created_at"`
+	Items          []StockTransferItem `json:"items,omitempty"`
+}
+
+type StockTransferItem struct {
+	WarehouseID int `json:"warehouse_id"`
+	Quantity    int `json:"quantity"`
+}
+
+// Reservation - резерв товара на локации под заказ до оплаты
+type Reservation struct {
+	ID          int       `json:"id"`
+	WarehouseID int       `json:"warehouse_id"`
+	LocationID  int       `json:"location_id"`
+	CustomerID  *int      `json:"customer_id"`
+	Quantity    int       `json:"quantity"`
+	Status      string    `json:"status"`
+	Note        string    `json:"note"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	SaleID      *int      `json:"sale_id"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Stocktake - инвентаризация товара на локации
+type Stocktake struct {
+	ID         int               `json:"id"`
+	LocationID int               `json:"location_id"`
+	Status     string            `json:"status"`
+	Note       string            `json:"note"`
+	CreatedBy  string            `json:"created_by"`
+	CreatedAt  time.Time         `json:"created_at"`
+	PostedBy   string            `json:"posted_by,omitempty"`
+	PostedAt   *time.Time        `json:"posted_at"`
+	Items      []StocktakeItem   `json:"items,omitempty"`
+	Summary    *StocktakeSummary `json:"summary,omitempty"`
+}
+
+// StocktakeItem - расхождение посчитанного количества с учетным
+type StocktakeItem struct {
+	WarehouseID     int     `json:"warehouse_id"`
+	ProductName     string  `json:"product_name"`
+	SystemQuantity  int     `json:"system_quantity"`
+	CountedQuantity int     `json:"counted_quantity"`
+	Variance        int     `json:"variance"`
+	UnitValue       float64 `json:"unit_value"`
+	VarianceValue   float64 `json:"variance_value"`
+}
+
+// StocktakeSummary - итоги инвентаризации: недостача, излишки и чистое расхождение в деньгах
+type StocktakeSummary struct {
+	ShortageQuantity int     `json:"shortage_quantity"`
+	ShortageValue    float64 `json:"shortage_value"`
+	SurplusQuantity  int     `json:"surplus_quantity"`
+	SurplusValue     float64 `json:"surplus_value"`
+	NetValue         float64 `json:"net_value"`
+}
+
+// LowStockItem - товар с остатком на уровне точки заказа или ниже.
+// Поставщик - основной поставщик товара, если он назначен.
+type LowStockItem struct {
+	WarehouseID       int      `json:"warehouse_id"`
+	Name              string   `json:"name"`
+	SKU               string   `json:"sku"`
+	Quantity          int      `json:"quantity"`
+	ReorderPoint      int      `json:"reorder_point"`
+	ReorderQuantity   int      `json:"reorder_quantity"`
+	SuggestedQuantity int      `json:"suggested_quantity"`
+	SupplierID        *int     `json:"supplier_id,omitempty"`
+	SupplierName      string   `json:"supplier_name,omitempty"`
+	SupplierSKU       string   `json:"supplier_sku,omitempty"`
+	LastPurchasePrice *float64 `json:"last_purchase_price,omitempty"`
+}
+
+// PurchaseListGroup - рекомендуемая закупка у одного поставщика.
+// EstimatedCost считается по последним закупочным ценам, товары без цены не учитываются.
+type PurchaseListGroup struct {
+	SupplierID       *int           `json:"supplier_id"`
+	SupplierName     string         `json:"supplier_name"`
+	PaymentTermsDays int            `json:"payment_terms_days"`
+	Items            []LowStockItem `json:"items"`
+	TotalQuantity    int            `json:"total_quantity"`
+	EstimatedCost    float64        `json:"estimated_cost"`
+}
+
+// Event - запись журнала событий
+type Event struct {
+	ID        int             `json:"id"`
+	Type      string          `json:"type"`
+	Message   string          `json:"message"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
 }
 
 type Charge struct {
+	ID             int       `json:"id"`
+	ExpenseItemID  int       `json:"expense_item_id"`
+	SupplierID     *int      `json:"supplier_id"`
+	Amount         float64   `json:"amount"`
+	ChargeDate     time.Time `json:"charge_date"`
+	Description    string    `json:"description"`
+	DocumentNumber string    `json:"document_number"`
+	// Status - pending (ждет утверждения), approved или rejected. В отчетах учитываются только утвержденные.
+	Status        string     `json:"status"`
+	ReviewedBy    string     `json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+	ReviewComment string     `json:"review_comment,omitempty"`
+	// BudgetExceeded - расходы статьи за месяц превысили бюджет (только в ответе на запись)
+	BudgetExceeded bool `json:"budget_exceeded,omitempty"`
+}
+
+// Attachment - файл (скан счета, акта, чека), приложенный к расходу, продаже или приемке партии.
+// OwnerType - charge, sale или lot, OwnerID - ID владельца.
+type Attachment struct {
+	ID          int       `json:"id"`
+	OwnerType   string    `json:"owner_type"`
+	OwnerID     int       `json:"owner_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	StorageKey  string    `json:"-"`
+	UploadedBy  string    `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AccountingPeriod - учетный период (месяц). Status: open или closed, в закрытом периоде
+// изменения запрещены. Snapshot - итоги периода на момент последнего закрытия.
+type AccountingPeriod struct {
+	Year       int             `json:"year"`
+	Month      int             `json:"month"`
+	Status     string          `json:"status"`
+	Snapshot   *PeriodSnapshot `json:"snapshot,omitempty"`
+	ClosedBy   *string         `json:"closed_by,omitempty"`
+	ClosedAt   *time.Time      `json:"closed_at,omitempty"`
+	ReopenedBy *string         `json:"reopened_by,omitempty"`
+	ReopenedAt *time.Time      `json:"reopened_at,omitempty"`
+}
+
+// PeriodSnapshot - итоги отчетов за период, сохраняемые при его закрытии.
+// StockAdjustments - стоимость корректировок остатков (инвентаризации, списания), недостача со знаком минус.
+type PeriodSnapshot struct {
+	Revenue          float64 `json:"revenue"`
+	GrossRevenue     float64 `json:"gross_revenue"`
+	Discounts        float64 `json:"discounts"`
+	Tax              float64 `json:"tax"`
+	Expenses         float64 `json:"expenses"`
+	Profit           float64 `json:"profit"`
+	SalesCount       int     `json:"sales_count"`
+	ChargesCount     int     `json:"charges_count"`
+	StockAdjustments float64 `json:"stock_adjustments"`
+}
+
+// Account - счет плана счетов. Type: active, passive или active_passive.
+type Account struct {
+	ID   int    `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// PaymentMethodAccount - счет учета денег, поступающих способом оплаты Method
+type PaymentMethodAccount struct {
+	Method      string `json:"method"`
+	AccountCode string `json:"account_code"`
+	AccountName string `json:"account_name"`
+}
+
+// JournalEntry - проводка: дебет и кредит счетов на сумму. SourceType и SourceID - документ
+// (sale, refund, purchase или charge), которым сделана проводка.
+type JournalEntry struct {
+	ID            int       `json:"id"`
+	EntryDate     time.Time `json:"entry_date"`
+	DebitAccount  string    `json:"debit_account"`
+	CreditAccount string    `json:"credit_account"`
+	Amount        float64   `json:"amount"`
+	SourceType    string    `json:"source_type"`
+	SourceID      int       `json:"source_id"`
+	Description   string    `json:"description"`
+}
+
+// TrialBalanceRow - строка оборотно-сальдовой ведомости по счету за период
+type TrialBalanceRow struct {
+	AccountCode    string  `json:"account_code,omitempty"`
+	AccountName    string  `json:"account_name,omitempty"`
+	OpeningDebit   float64 `json:"opening_debit"`
+	OpeningCredit  float64 `json:"opening_credit"`
+	DebitTurnover  float64 `json:"debit_turnover"`
+	CreditTurnover float64 `json:"credit_turnover"`
+	ClosingDebit   float64 `json:"closing_debit"`
+	ClosingCredit  float64 `json:"closing_credit"`
+}
+
+// Budget - бюджет статьи расходов на месяц. HardLimit запрещает расходы сверх бюджета.
+type Budget struct {
 	ID            int       `json:"id"`
 	ExpenseItemID int       `json:"expense_item_id"`
+	Year          int       `json:"year"`
+	Month         int       `json:"month"`
 	Amount        float64   `json:"amount"`
-	ChargeDate    time.Time `json:"charge_date"`
+	HardLimit     bool      `json:"hard_limit"`
+	CreatedBy     string    `json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// RecurringCharge - шаблон повторяющегося расхода. Frequency: monthly (в день месяца DayOfMonth)
+// или weekly (в день недели DayOfWeek, 0 - воскресенье).
+type RecurringCharge struct {
+	ID             int        `json:"id"`
+	Name           string     `json:"name"`
+	ExpenseItemID  int        `json:"expense_item_id"`
+	SupplierID     *int       `json:"supplier_id"`
+	Amount         float64    `json:"amount"`
+	Frequency      string     `json:"frequency"`
+	DayOfMonth     *int       `json:"day_of_month"`
+	DayOfWeek      *int       `json:"day_of_week"`
+	StartsOn       time.Time  `json:"starts_on"`
+	EndsOn         *time.Time `json:"ends_on"`
+	IsActive       bool       `json:"is_active"`
+	NextOccurrence *time.Time `json:"next_occurrence"`
+	CreatedBy      string     `json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// RecurringChargeOccurrence - дата повторения шаблона. ChargeID - расход, уже созданный за эту дату.
+type RecurringChargeOccurrence struct {
+	Date     time.Time `json:"date"`
+	Amount   float64   `json:"amount"`
+	ChargeID *int      `json:"charge_id"`
 }
 
 type ExpenseItem struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	ParentID *int   `json:"parent_id"`
+	// ApprovalThreshold - сумма, выше которой расход требует утверждения (nil - порог родительской статьи)
+	ApprovalThreshold *float64 `json:"approval_threshold"`
+	// AccountCode - счет затрат для проводок (nil - счет родительской статьи, по умолчанию 44)
+	AccountCode *string        `json:"account_code"`
+	Children    []*ExpenseItem `json:"children,omitempty"`
+}
+
+// ExpenseReportItem - расходы статьи за период: Own - на саму статью, Total - вместе с подстатьями
+type ExpenseReportItem struct {
+	ExpenseItemID int                  `json:"expense_item_id"`
+	Name          string               `json:"name"`
+	ParentID      *int                 `json:"parent_id"`
+	Own           float64              `json:"own"`
+	Total         float64              `json:"total"`
+	Children      []*ExpenseReportItem `json:"children,omitempty"`
+}
+
+// DashboardTotals - показатели сводки за период: выручка, утвержденные расходы, прибыль,
+// число продаж и средний чек
+type DashboardTotals struct {
+	Revenue       float64 `json:"revenue"`
+	Expenses      float64 `json:"expenses"`
+	Profit        float64 `json:"profit"`
+	SalesCount    int     `json:"sales_count"`
+	AverageTicket float64 `json:"average_ticket"`
+}
+
+// DashboardProduct - товар из топа продаж сводки
+type DashboardProduct struct {
+	WarehouseID int     `json:"warehouse_id"`
+	Name        string  `json:"name"`
+	Quantity    int     `json:"quantity"`
+	Revenue     float64 `json:"revenue"`
+}
+
+// CashflowDay - движение денег за день: поступления (всего и по способам оплаты), выплаты,
+// сальдо дня и нарастающий итог с начала периода отчета
+type CashflowDay struct {
+	Date       string             `json:"date"`
+	In         float64            `json:"in"`
+	InByMethod map[string]float64 `json:"in_by_method"`
+	Out        float64            `json:"out"`
+	Net        float64            `json:"net"`
+	Balance    float64            `json:"balance"`
+}
+
+// Delta - отклонение показателя от периода сравнения: абсолютное и в процентах
+// (Percent - nil, если в периоде сравнения показатель нулевой)
+type Delta struct {
+	Absolute float64  `json:"absolute"`
+	Percent  *float64 `json:"percent"`
+}
+
+// ABCXYZItem - класс товара в ABC/XYZ-анализе и показатели, по которым он определен:
+// доля и накопленная доля выручки (в процентах), среднее и стандартное отклонение недельного
+// спроса в штуках и коэффициент вариации (в процентах, nil - продаж не было)
+type ABCXYZItem struct {
+	WarehouseID     int      `json:"warehouse_id"`
+	Name            string   `json:"name"`
+	Revenue         float64  `json:"revenue"`
+	RevenueShare    float64  `json:"revenue_share"`
+	CumulativeShare float64  `json:"cumulative_share"`
+	ABC             string   `json:"abc"`
+	WeeklyMean      float64  `json:"weekly_mean"`
+	WeeklyStdDev    float64  `json:"weekly_std_dev"`
+	Variation       *float64 `json:"variation"`
+	XYZ             string   `json:"xyz"`
+	Class           string   `json:"class"`
+}
+
+// DemandForecast - прогноз спроса на товар по дням и рекомендация по закупке: Forecast - прогноз
+// на каждый день горизонта, StockoutDays - через сколько дней закончится остаток (nil - хватит
+// на горизонт и срок поставки), SuggestedQuantity - сколько заказать (0 - заказывать рано)
+type DemandForecast struct {
+	WarehouseID       int       `json:"warehouse_id"`
+	Name              string    `json:"name"`
+	SKU               string    `json:"sku"`
+	Quantity          int       `json:"quantity"`
+	SupplierID        *int      `json:"supplier_id"`
+	SupplierName      string    `json:"supplier_name"`
+	LeadTimeDays      int       `json:"lead_time_days"`
+	AverageDaily      float64   `json:"average_daily"`
+	Forecast          []float64 `json:"forecast"`
+	ForecastTotal     float64   `json:"forecast_total"`
+	LeadTimeDemand    float64   `json:"lead_time_demand"`
+	SafetyStock       float64   `json:"safety_stock"`
+	ReorderPoint      float64   `json:"reorder_point"`
+	StockoutDays      *int      `json:"stockout_days"`
+	SuggestedQuantity int       `json:"suggested_quantity"`
+}
+
+// SalesTotals - число продаж, проданное количество и выручка
+type SalesTotals struct {
+	Count    int     `json:"count"`
+	Quantity int     `json:"quantity"`
+	Revenue  float64 `json:"revenue"`
+}
+
+// SalesHeatmap - продажи по дням недели и часам в часовом поясе магазина. Матрицы Count,
+// Quantity и Revenue - 7 строк (понедельник - воскресенье) по 24 часа.
+type SalesHeatmap struct {
+	TimeZone  string          `json:"timezone"`
+	Weekdays  []string        `json:"weekdays"`
+	Count     [7][24]int      `json:"count"`
+	Quantity  [7][24]int      `json:"quantity"`
+	Revenue   [7][24]float64  `json:"revenue"`
+	ByWeekday [7]SalesTotals  `json:"by_weekday"`
+	ByHour    [24]SalesTotals `json:"by_hour"`
+	Total     SalesTotals     `json:"total"`
 }
 
 type APIResponse struct {
@@ -0,0 +1,123 @@
+// notify/notify.go
+package notify
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"store_app/internal/config"
+	"time"
+)
+
+// Типы событий
+const (
+	EventLowStock       = "low_stock"
+	EventBudgetExceeded = "budget_exceeded"
+)
+
+// Event - событие, о котором нужно оповестить (низкий остаток и т.п.)
+type Event struct {
+	Type      string      `json:"type"`
+	Message   string      `json:"message"`
+	Payload   interface{} `json:"payload,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Notifier доставляет событие получателю
+type Notifier interface {
+	Notify(e Event) error
+}
+
+// LogNotifier пишет события в лог приложения
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(e Event) error {
+	log.Printf("[%s] %s", e.Type, e.Message)
+	return nil
+}
+
+// DBNotifier сохраняет события в таблицу events
+type DBNotifier struct {
+	DB *sql.DB
+}
+
+func (n DBNotifier) Notify(e Event) error {
+	var payload []byte
+	if e.Payload != nil {
+		var err error
+		if payload, err = json.Marshal(e.Payload); err != nil {
+			return err
+		}
+	}
+
+	_, err := n.DB.Exec(
+		"INSERT INTO events (type, message, payload, created_at) VALUES ($1, $2, $3, $4)",
+		e.Type, e.Message, payload, e.CreatedAt,
+	)
+	return err
+}
+
+// WebhookNotifier отправляет событие POST-запросом с JSON на внешний URL
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func (n WebhookNotifier) Notify(e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.Client.Post(n.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Multi рассылает событие всем получателям, ошибка одного не мешает остальным
+type Multi []Notifier
+
+func (m Multi) Notify(e Event) error {
+	var firstErr error
+	for _, n := range m {
+		if err := n.Notify(e); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// New собирает получателей по конфигурации: лог и журнал в БД всегда,
+// вебхук - если задан ALERT_WEBHOOK_URL
+func New(db *sql.DB, cfg config.AlertsConfig) Notifier {
+	m := Multi{LogNotifier{}, DBNotifier{DB: db}}
+	if cfg.WebhookURL != "" {
+		m = append(m, WebhookNotifier{
+			URL:    cfg.WebhookURL,
+			Client: &http.Client{Timeout: cfg.WebhookTimeout},
+		})
+	}
+	return m
+}
+
+// Send доставляет событие в фоне, чтобы медленный получатель не задерживал ответ API
+func Send(n Notifier, e Event) {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	go func() {
+		if err := n.Notify(e); err != nil {
+			log.Printf("Failed to deliver %s event: %v", e.Type, err)
+		}
+	}()
+}
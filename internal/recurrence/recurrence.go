@@ -0,0 +1,104 @@
+// Package recurrence рассчитывает даты повторяющихся событий по расписанию
+package recurrence
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	Monthly = "monthly"
+	Weekly  = "weekly"
+)
+
+// Schedule - расписание повторения. Все даты - календарные дни (полночь UTC).
+// Monthly повторяется в день месяца DayOfMonth (в коротких месяцах - в последний день),
+// Weekly - в день недели DayOfWeek (0 - воскресенье, как time.Weekday).
+type Schedule struct {
+	Frequency  string
+	DayOfMonth int
+	DayOfWeek  int
+	StartsOn   time.Time
+	EndsOn     *time.Time
+}
+
+// Date возвращает календарную дату момента t
+func Date(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Validate проверяет расписание
+func (s Schedule) Validate() error {
+	switch s.Frequency {
+	case Monthly:
+		if s.DayOfMonth < 1 || s.DayOfMonth > 31 {
+			return errors.New("для ежемесячного расписания нужен день месяца от 1 до 31")
+		}
+	case Weekly:
+		if s.DayOfWeek < 0 || s.DayOfWeek > 6 {
+			return errors.New("для еженедельного расписания нужен день недели от 0 (воскресенье) до 6")
+		}
+	default:
+		return errors.New("неизвестная периодичность")
+	}
+
+	if s.EndsOn != nil && s.EndsOn.Before(s.StartsOn) {
+		return errors.New("дата окончания раньше даты начала")
+	}
+	return nil
+}
+
+// Next возвращает первую дату повторения не раньше from.
+// false означает, что расписание закончилось.
+func (s Schedule) Next(from time.Time) (time.Time, bool) {
+	from = Date(from)
+	if from.Before(s.StartsOn) {
+		from = Date(s.StartsOn)
+	}
+
+	var next time.Time
+	switch s.Frequency {
+	case Weekly:
+		next = from.AddDate(0, 0, (s.DayOfWeek-int(from.Weekday())+7)%7)
+	default:
+		next = monthDay(from.Year(), from.Month(), s.DayOfMonth)
+		if next.Before(from) {
+			next = monthDay(from.Year(), from.Month()+1, s.DayOfMonth)
+		}
+	}
+
+	if s.EndsOn != nil && next.After(Date(*s.EndsOn)) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// Between возвращает даты повторения в интервале [from, to]
+func (s Schedule) Between(from, to time.Time) []time.Time {
+	var dates []time.Time
+	to = Date(to)
+	for d, ok := s.Next(from); ok && !d.After(to); d, ok = s.Next(d.AddDate(0, 0, 1)) {
+		dates = append(dates, d)
+	}
+	return dates
+}
+
+// NextN возвращает до n ближайших дат повторения не раньше from
+func (s Schedule) NextN(from time.Time, n int) []time.Time {
+	var dates []time.Time
+	for d, ok := s.Next(from); ok && len(dates) < n; d, ok = s.Next(d.AddDate(0, 0, 1)) {
+		dates = append(dates, d)
+	}
+	return dates
+}
+
+// monthDay возвращает день day месяца, в коротких месяцах - последний день.
+// month может выходить за 12, как в time.Date.
+func monthDay(year int, month time.Month, day int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
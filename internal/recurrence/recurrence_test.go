@@ -0,0 +1,69 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestNextMonthEnd(t *testing.T) {
+	s := Schedule{Frequency: Monthly, DayOfMonth: 31, StartsOn: date(2025, time.January, 1)}
+
+	tests := []struct {
+		name string
+		from time.Time
+		want time.Time
+	}{
+		{"31-е число", date(2026, time.January, 10), date(2026, time.January, 31)},
+		{"в день повторения", date(2026, time.January, 31), date(2026, time.January, 31)},
+		{"февраль", date(2026, time.February, 1), date(2026, time.February, 28)},
+		{"февраль високосного года", date(2028, time.February, 1), date(2028, time.February, 29)},
+		{"30 дней", date(2026, time.April, 15), date(2026, time.April, 30)},
+		{"после короткого месяца", date(2026, time.March, 1), date(2026, time.March, 31)},
+		{"время внутри дня", date(2026, time.December, 31).Add(time.Hour), date(2026, time.December, 31)},
+		{"до начала расписания", date(2024, time.June, 1), date(2025, time.January, 31)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := s.Next(tt.from)
+			if !ok || !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, %v; ожидалось %s", tt.from.Format("2006-01-02"), got.Format("2006-01-02"), ok, tt.want.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestBetweenMonthEnd(t *testing.T) {
+	// Последний день короткого месяца не должен сдвигать следующие даты
+	s := Schedule{Frequency: Monthly, DayOfMonth: 31, StartsOn: date(2026, time.January, 1)}
+	want := []time.Time{
+		date(2026, time.January, 31),
+		date(2026, time.February, 28),
+		date(2026, time.March, 31),
+		date(2026, time.April, 30),
+		date(2026, time.May, 31),
+	}
+
+	got := s.Between(date(2026, time.January, 1), date(2026, time.May, 31))
+	if len(got) != len(want) {
+		t.Fatalf("Between вернул %d дат, ожидалось %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("дата %d: %s, ожидалось %s", i, got[i].Format("2006-01-02"), want[i].Format("2006-01-02"))
+		}
+	}
+}
+
+func TestNextEndsOn(t *testing.T) {
+	endsOn := date(2026, time.February, 27)
+	s := Schedule{Frequency: Monthly, DayOfMonth: 31, StartsOn: date(2026, time.January, 1), EndsOn: &endsOn}
+
+	if got, ok := s.Next(date(2026, time.February, 1)); ok {
+		t.Errorf("Next после окончания расписания вернул %s", got.Format("2006-01-02"))
+	}
+}
@@ -25,6 +25,22 @@ func SetupRouter(db *sql.DB) *gin.Engine {
 	salesHandler := handlers.NewSalesHandler(db)
 	chargesHandler := handlers.NewChargesHandler(db)
 	expenseItemsHandler := handlers.NewExpenseItemsHandler(db)
+	customersHandler := handlers.NewCustomersHandler(db)
+	promotionsHandler := handlers.NewPromotionsHandler(db)
+	categoriesHandler := handlers.NewCategoriesHandler(db)
+	locationsHandler := handlers.NewLocationsHandler(db)
+	transfersHandler := handlers.NewTransfersHandler(db)
+	eventsHandler := handlers.NewEventsHandler(db)
+	reservationsHandler := handlers.NewReservationsHandler(db)
+	stocktakesHandler := handlers.NewStocktakesHandler(db)
+	lotsHandler := handlers.NewLotsHandler(db)
+	pricesHandler := handlers.NewPricesHandler(db)
+	suppliersHandler := handlers.NewSuppliersHandler(db)
+	recurringChargesHandler := handlers.NewRecurringChargesHandler(db)
+	budgetsHandler := handlers.NewBudgetsHandler(db)
+	attachmentsHandler := handlers.NewAttachmentsHandler(db)
+	periodsHandler := handlers.NewPeriodsHandler(db)
+	ledgerHandler := handlers.NewLedgerHandler(db)
 
 	// ДОБАВЛЕНО: обработчики отчетов
 	reportsHandler := handlers.NewReportsHandler(db)
@@ -44,29 +60,166 @@ func SetupRouter(db *sql.DB) *gin.Engine {
 
 			// Warehouses (товары)
 			auth.GET("/warehouses", warehousesHandler.GetWarehouses)
+			auth.GET("/warehouses/by-barcode/:code", warehousesHandler.GetWarehouseByBarcode)
+			auth.GET("/warehouses/low-stock", warehousesHandler.GetLowStock)
+			auth.GET("/warehouses/purchase-list", warehousesHandler.GetPurchaseList)
 			auth.GET("/warehouses/:id", warehousesHandler.GetWarehouse)
+			auth.GET("/warehouses/:id/stock", warehousesHandler.GetWarehouseStock)
+			auth.GET("/warehouses/:id/lots", lotsHandler.GetWarehouseLots)
+			auth.POST("/warehouses/:id/lots", lotsHandler.ReceiveLot)
+			auth.GET("/warehouses/:id/prices", pricesHandler.GetPriceHistory)
+			auth.POST("/warehouses/:id/prices", pricesHandler.SchedulePriceChange)
+			auth.DELETE("/warehouses/:id/prices/:price_id", pricesHandler.CancelPriceChange)
+			auth.GET("/warehouses/:id/suppliers", suppliersHandler.GetWarehouseSuppliers)
+			auth.PUT("/warehouses/:id/suppliers/:supplier_id", suppliersHandler.SetWarehouseSupplier)
+			auth.DELETE("/warehouses/:id/suppliers/:supplier_id", suppliersHandler.DeleteWarehouseSupplier)
 			auth.POST("/warehouses", warehousesHandler.CreateWarehouse)
 			auth.PUT("/warehouses/:id", warehousesHandler.UpdateWarehouse)
 			auth.DELETE("/warehouses/:id", warehousesHandler.DeleteWarehouse)
 
+			// Categories (категории товаров)
+			auth.GET("/categories", categoriesHandler.GetCategories)
+			auth.GET("/categories/:id", categoriesHandler.GetCategory)
+			auth.POST("/categories", categoriesHandler.CreateCategory)
+			auth.PUT("/categories/:id", categoriesHandler.UpdateCategory)
+			auth.DELETE("/categories/:id", categoriesHandler.DeleteCategory)
+
+			// Locations (торговые точки и склады)
+			auth.GET("/locations", locationsHandler.GetLocations)
+			auth.GET("/locations/:id", locationsHandler.GetLocation)
+			auth.GET("/locations/:id/stock", locationsHandler.GetLocationStock)
+			auth.POST("/locations", middleware.RequireRole("admin"), locationsHandler.CreateLocation)
+			auth.PUT("/locations/:id", middleware.RequireRole("admin"), locationsHandler.UpdateLocation)
+			auth.DELETE("/locations/:id", middleware.RequireRole("admin"), locationsHandler.DeleteLocation)
+
+			// Suppliers (поставщики)
+			auth.GET("/suppliers", suppliersHandler.GetSuppliers)
+			auth.GET("/suppliers/:id", suppliersHandler.GetSupplier)
+			auth.GET("/suppliers/:id/products", suppliersHandler.GetSupplierProducts)
+			auth.POST("/suppliers", suppliersHandler.CreateSupplier)
+			auth.PUT("/suppliers/:id", suppliersHandler.UpdateSupplier)
+			auth.DELETE("/suppliers/:id", suppliersHandler.DeleteSupplier)
+
+			// Lots (партии со сроком годности), списывать может только администратор
+			auth.POST("/lots/:id/write-off", middleware.RequireRole("admin"), lotsHandler.WriteOffLot)
+			auth.GET("/lots/:id/attachments", attachmentsHandler.GetLotAttachments)
+			auth.POST("/lots/:id/attachments", attachmentsHandler.UploadLotAttachment)
+
+			// Transfers (перемещения между локациями)
+			auth.GET("/transfers", transfersHandler.GetTransfers)
+			auth.GET("/transfers/:id", transfersHandler.GetTransfer)
+			auth.POST("/transfers", transfersHandler.CreateTransfer)
+
+			// Stocktakes (инвентаризации), проводить и отменять может только администратор
+			auth.GET("/stocktakes", stocktakesHandler.GetStocktakes)
+			auth.GET("/stocktakes/:id", stocktakesHandler.GetStocktake)
+			auth.POST("/stocktakes", stocktakesHandler.CreateStocktake)
+			auth.POST("/stocktakes/:id/counts", stocktakesHandler.SubmitCounts)
+			auth.POST("/stocktakes/:id/post", middleware.RequireRole("admin"), stocktakesHandler.PostStocktake)
+			auth.POST("/stocktakes/:id/cancel", middleware.RequireRole("admin"), stocktakesHandler.CancelStocktake)
+
 			// Sales (продажи)
 			auth.GET("/sales", salesHandler.GetSales)
 			auth.POST("/sales", salesHandler.CreateSale)
 			auth.DELETE("/sales/:id", salesHandler.DeleteSale)
+			auth.GET("/sales/:id/attachments", attachmentsHandler.GetSaleAttachments)
+			auth.POST("/sales/:id/attachments", attachmentsHandler.UploadSaleAttachment)
+
+			// Reservations (резервы товара под заказы)
+			auth.GET("/reservations", reservationsHandler.GetReservations)
+			auth.GET("/reservations/:id", reservationsHandler.GetReservation)
+			auth.POST("/reservations", reservationsHandler.CreateReservation)
+			auth.POST("/reservations/:id/release", reservationsHandler.ReleaseReservation)
+			auth.POST("/reservations/:id/convert", reservationsHandler.ConvertReservation)
 
 			// Charges (расходы)
 			auth.GET("/charges", chargesHandler.GetCharges)
 			auth.POST("/charges", chargesHandler.CreateCharge)
+			auth.PUT("/charges/:id", chargesHandler.UpdateCharge)
+			auth.PATCH("/charges/:id", chargesHandler.PatchCharge)
 			auth.DELETE("/charges/:id", chargesHandler.DeleteCharge)
+			auth.POST("/charges/:id/approve", middleware.RequireRole("admin"), chargesHandler.ApproveCharge)
+			auth.POST("/charges/:id/reject", middleware.RequireRole("admin"), chargesHandler.RejectCharge)
+			auth.GET("/charges/:id/attachments", attachmentsHandler.GetChargeAttachments)
+			auth.POST("/charges/:id/attachments", attachmentsHandler.UploadChargeAttachment)
+
+			// Attachments (вложения)
+			auth.GET("/attachments/:id/download", attachmentsHandler.DownloadAttachment)
+			auth.DELETE("/attachments/:id", attachmentsHandler.DeleteAttachment)
+
+			// Recurring Charges (повторяющиеся расходы)
+			auth.GET("/recurring-charges", recurringChargesHandler.GetRecurringCharges)
+			auth.GET("/recurring-charges/:id", recurringChargesHandler.GetRecurringCharge)
+			auth.GET("/recurring-charges/:id/preview", recurringChargesHandler.PreviewRecurringCharge)
+			auth.POST("/recurring-charges", recurringChargesHandler.CreateRecurringCharge)
+			auth.PUT("/recurring-charges/:id", recurringChargesHandler.UpdateRecurringCharge)
+			auth.DELETE("/recurring-charges/:id", recurringChargesHandler.DeleteRecurringCharge)
+
+			// Budgets (бюджеты статей расходов)
+			auth.GET("/budgets", budgetsHandler.GetBudgets)
+			auth.POST("/budgets", middleware.RequireRole("admin"), budgetsHandler.CreateBudget)
+			auth.PUT("/budgets/:id", middleware.RequireRole("admin"), budgetsHandler.UpdateBudget)
+			auth.DELETE("/budgets/:id", middleware.RequireRole("admin"), budgetsHandler.DeleteBudget)
+
+			// Accounting Periods (учетные периоды), закрывать и открывать может только администратор
+			auth.GET("/periods", periodsHandler.GetPeriods)
+			auth.GET("/periods/:year/:month", periodsHandler.GetPeriod)
+			auth.POST("/periods/:year/:month/close", middleware.RequireRole("admin"), periodsHandler.ClosePeriod)
+			auth.POST("/periods/:year/:month/reopen", middleware.RequireRole("admin"), periodsHandler.ReopenPeriod)
+
+			// Ledger (проводки), план счетов и счета способов оплаты меняет только администратор
+			auth.GET("/ledger/accounts", ledgerHandler.GetAccounts)
+			auth.POST("/ledger/accounts", middleware.RequireRole("admin"), ledgerHandler.CreateAccount)
+			auth.PUT("/ledger/accounts/:id", middleware.RequireRole("admin"), ledgerHandler.UpdateAccount)
+			auth.GET("/ledger/payment-accounts", ledgerHandler.GetPaymentMethodAccounts)
+			auth.PUT("/ledger/payment-accounts/:method", middleware.RequireRole("admin"), ledgerHandler.SetPaymentMethodAccount)
+			auth.GET("/ledger/entries", ledgerHandler.GetJournalEntries)
+			auth.GET("/ledger/trial-balance", ledgerHandler.GetTrialBalance)
 
 			// Expense Items (статьи расходов)
 			auth.GET("/expense-items", expenseItemsHandler.GetExpenseItems)
+			auth.GET("/expense-items/:id", expenseItemsHandler.GetExpenseItem)
 			auth.POST("/expense-items", expenseItemsHandler.CreateExpenseItem)
+			auth.PUT("/expense-items/:id", expenseItemsHandler.UpdateExpenseItem)
 			auth.DELETE("/expense-items/:id", expenseItemsHandler.DeleteExpenseItem)
 
+			// Customers (покупатели)
+			auth.GET("/customers", customersHandler.GetCustomers)
+			auth.GET("/customers/:id", customersHandler.GetCustomer)
+			auth.GET("/customers/:id/sales", customersHandler.GetCustomerSales)
+			auth.GET("/customers/:id/stats", customersHandler.GetCustomerStats)
+			auth.POST("/customers", customersHandler.CreateCustomer)
+			auth.PUT("/customers/:id", customersHandler.UpdateCustomer)
+			auth.DELETE("/customers/:id", customersHandler.DeleteCustomer)
+
+			// Promotions (промоакции), изменять может только администратор
+			auth.GET("/promotions", promotionsHandler.GetPromotions)
+			auth.GET("/promotions/:id", promotionsHandler.GetPromotion)
+			auth.POST("/promotions", middleware.RequireRole("admin"), promotionsHandler.CreatePromotion)
+			auth.PUT("/promotions/:id", middleware.RequireRole("admin"), promotionsHandler.UpdatePromotion)
+			auth.DELETE("/promotions/:id", middleware.RequireRole("admin"), promotionsHandler.DeletePromotion)
+
+			// Events (журнал событий и оповещений)
+			auth.GET("/events", middleware.RequireRole("admin"), eventsHandler.GetEvents)
+
 			// ДОБАВЛЕНО: Reports (отчеты)
 			auth.GET("/reports/profit", reportsHandler.GetProfitReport)
 			auth.GET("/reports/top-products", reportsHandler.GetTopProductsReport)
+			auth.GET("/reports/top-customers", reportsHandler.GetTopCustomersReport)
+			auth.GET("/reports/discounts", reportsHandler.GetDiscountsReport)
+			auth.GET("/reports/payment-methods", reportsHandler.GetPaymentMethodsReport)
+			auth.GET("/reports/z-report", reportsHandler.GetZReport)
+			auth.GET("/reports/tax", reportsHandler.GetTaxReport)
+			auth.GET("/reports/shrinkage", reportsHandler.GetShrinkageReport)
+			auth.GET("/reports/expiring-lots", reportsHandler.GetExpiringLotsReport)
+			auth.GET("/reports/supplier-spend", reportsHandler.GetSupplierSpendReport)
+			auth.GET("/reports/budget", reportsHandler.GetBudgetReport)
+			auth.GET("/reports/expenses", reportsHandler.GetExpensesReport)
+			auth.GET("/reports/dashboard", reportsHandler.GetDashboardReport)
+			auth.GET("/reports/cashflow", reportsHandler.GetCashflowReport)
+			auth.GET("/reports/abc-xyz", reportsHandler.GetABCXYZReport)
+			auth.GET("/reports/forecast", reportsHandler.GetForecastReport)
+			auth.GET("/reports/sales-heatmap", reportsHandler.GetSalesHeatmapReport)
 		}
 	}
 
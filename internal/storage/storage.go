@@ -0,0 +1,98 @@
+// storage/storage.go
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound - файла с таким ключом нет в хранилище
+var ErrNotFound = errors.New("file not found")
+
+// ErrInvalidKey - ключ файла пустой или выходит за пределы хранилища
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage хранит содержимое файлов вложений. Метаданные (имя, тип, владелец) хранятся в БД,
+// хранилище знает только ключ файла.
+type Storage interface {
+	Save(key string, r io.Reader) (int64, error)
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// LocalStorage хранит файлы в каталоге локальной файловой системы
+type LocalStorage struct {
+	Dir string
+}
+
+// NewLocalStorage создает хранилище в каталоге dir, каталог создается при первой записи
+func NewLocalStorage(dir string) *LocalStorage {
+	return &LocalStorage{Dir: dir}
+}
+
+// path возвращает путь к файлу ключа. Ключи могут содержать подкаталоги, но не ".."
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.Dir, clean), nil
+}
+
+// Save записывает файл во временный файл и переименовывает его, чтобы при ошибке
+// в хранилище не оставался недописанный файл. Возвращает число записанных байт.
+func (s *LocalStorage) Save(key string, r io.Reader) (int64, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return n, err
+	}
+	if err := tmp.Close(); err != nil {
+		return n, err
+	}
+	return n, os.Rename(tmp.Name(), path)
+}
+
+// Open открывает файл для чтения
+func (s *LocalStorage) Open(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Delete удаляет файл, отсутствующий файл ошибкой не считается
+func (s *LocalStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
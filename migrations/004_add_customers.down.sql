@@ -0,0 +1,7 @@
+-- Удаление привязки продаж к покупателям
+DROP INDEX IF EXISTS idx_sales_customer_id;
+ALTER TABLE sales DROP CONSTRAINT IF EXISTS sales_customer_id_fkey;
+ALTER TABLE sales DROP COLUMN IF EXISTS customer_id;
+
+-- Удаление таблицы покупателей
+DROP TABLE IF EXISTS customers;
@@ -0,0 +1,21 @@
+-- Таблица покупателей
+CREATE TABLE IF NOT EXISTS customers (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    phone VARCHAR(20) NOT NULL DEFAULT '',
+    email VARCHAR(100) NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Индексы для поиска покупателя по телефону и email
+CREATE INDEX IF NOT EXISTS idx_customers_phone ON customers(phone);
+CREATE INDEX IF NOT EXISTS idx_customers_email ON customers(email);
+
+-- Привязка продажи к покупателю (необязательная)
+ALTER TABLE sales ADD COLUMN IF NOT EXISTS customer_id integer;
+ALTER TABLE sales DROP CONSTRAINT IF EXISTS sales_customer_id_fkey;
+ALTER TABLE sales ADD CONSTRAINT sales_customer_id_fkey FOREIGN KEY (customer_id)
+    REFERENCES customers (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_sales_customer_id ON sales(customer_id);
@@ -0,0 +1,8 @@
+-- Удаление полей скидок из продаж
+ALTER TABLE sales DROP CONSTRAINT IF EXISTS sales_promotion_id_fkey;
+ALTER TABLE sales DROP COLUMN IF EXISTS promotion_id;
+ALTER TABLE sales DROP COLUMN IF EXISTS discount_amount;
+ALTER TABLE sales DROP COLUMN IF EXISTS subtotal;
+
+-- Удаление таблицы промоакций
+DROP TABLE IF EXISTS promotions;
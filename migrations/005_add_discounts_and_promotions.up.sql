@@ -0,0 +1,31 @@
+-- Таблица промоакций
+CREATE TABLE IF NOT EXISTS promotions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    value NUMERIC NOT NULL DEFAULT 0,
+    buy_quantity INTEGER,
+    free_quantity INTEGER,
+    warehouse_id INTEGER,
+    starts_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ends_at TIMESTAMP,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT promotions_kind_check CHECK (kind IN ('percent', 'fixed', 'buy_x_get_y')),
+    CONSTRAINT promotions_warehouse_id_fkey FOREIGN KEY (warehouse_id)
+        REFERENCES warehouses (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotions_warehouse_id ON promotions(warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_promotions_period ON promotions(starts_at, ends_at);
+
+-- Сумма до скидки, примененная скидка и промоакция на продаже
+ALTER TABLE sales ADD COLUMN IF NOT EXISTS subtotal numeric;
+ALTER TABLE sales ADD COLUMN IF NOT EXISTS discount_amount numeric NOT NULL DEFAULT 0;
+ALTER TABLE sales ADD COLUMN IF NOT EXISTS promotion_id integer;
+ALTER TABLE sales DROP CONSTRAINT IF EXISTS sales_promotion_id_fkey;
+ALTER TABLE sales ADD CONSTRAINT sales_promotion_id_fkey FOREIGN KEY (promotion_id)
+    REFERENCES promotions (id) ON DELETE SET NULL;
+
+-- Для существующих продаж сумма до скидки равна сумме продажи
+UPDATE sales SET subtotal = amount WHERE subtotal IS NULL;
@@ -0,0 +1,6 @@
+-- Удаление таблицы оплат
+DROP TABLE IF EXISTS sale_payments;
+
+-- Удаление кассира из продаж
+DROP INDEX IF EXISTS idx_sales_cashier;
+ALTER TABLE sales DROP COLUMN IF EXISTS cashier;
@@ -0,0 +1,17 @@
+-- Кассир, оформивший продажу (имя пользователя из JWT)
+ALTER TABLE sales ADD COLUMN IF NOT EXISTS cashier VARCHAR(50);
+CREATE INDEX IF NOT EXISTS idx_sales_cashier ON sales(cashier);
+
+-- Таблица оплат продаж (одна продажа может быть оплачена несколькими способами)
+CREATE TABLE IF NOT EXISTS sale_payments (
+    id SERIAL PRIMARY KEY,
+    sale_id integer NOT NULL,
+    method VARCHAR(20) NOT NULL,
+    amount numeric NOT NULL,
+    CONSTRAINT sale_payments_method_check CHECK (method IN ('cash', 'card', 'transfer', 'gift_card')),
+    CONSTRAINT sale_payments_amount_check CHECK (amount > 0),
+    CONSTRAINT sale_payments_sale_id_fkey FOREIGN KEY (sale_id)
+        REFERENCES sales (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_sale_payments_sale_id ON sale_payments(sale_id);
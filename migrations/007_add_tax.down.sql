@@ -0,0 +1,9 @@
+-- Удаление налоговых полей продаж
+DROP INDEX IF EXISTS idx_sales_tax_rate;
+ALTER TABLE sales DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE sales DROP COLUMN IF EXISTS net_amount;
+ALTER TABLE sales DROP COLUMN IF EXISTS tax_rate;
+
+-- Удаление ставки налога товаров
+ALTER TABLE warehouses DROP CONSTRAINT IF EXISTS warehouses_tax_rate_check;
+ALTER TABLE warehouses DROP COLUMN IF EXISTS tax_rate;
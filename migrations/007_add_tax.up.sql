@@ -0,0 +1,15 @@
+-- Ставка НДС товара в процентах (0 - без налога / нулевая ставка)
+ALTER TABLE warehouses ADD COLUMN IF NOT EXISTS tax_rate numeric NOT NULL DEFAULT 0;
+ALTER TABLE warehouses DROP CONSTRAINT IF EXISTS warehouses_tax_rate_check;
+ALTER TABLE warehouses ADD CONSTRAINT warehouses_tax_rate_check CHECK (tax_rate >= 0 AND tax_rate < 100);
+
+-- Налоговая часть продажи: ставка на момент продажи, сумма без налога и сумма налога.
+-- Поле amount остается итоговой суммой с налогом.
+ALTER TABLE sales ADD COLUMN IF NOT EXISTS tax_rate numeric NOT NULL DEFAULT 0;
+ALTER TABLE sales ADD COLUMN IF NOT EXISTS net_amount numeric;
+ALTER TABLE sales ADD COLUMN IF NOT EXISTS tax_amount numeric NOT NULL DEFAULT 0;
+
+-- Существующие продажи считаем оформленными без налога
+UPDATE sales SET net_amount = amount WHERE net_amount IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_sales_tax_rate ON sales(tax_rate);
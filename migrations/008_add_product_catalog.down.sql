@@ -0,0 +1,18 @@
+-- Удаление привязки акций к категориям
+ALTER TABLE IF EXISTS promotions DROP CONSTRAINT IF EXISTS promotions_category_id_fkey;
+ALTER TABLE IF EXISTS promotions DROP COLUMN IF EXISTS category_id;
+
+-- Удаление атрибутов каталога
+DROP INDEX IF EXISTS idx_warehouses_category_id;
+DROP INDEX IF EXISTS idx_warehouses_barcode;
+DROP INDEX IF EXISTS idx_warehouses_sku;
+ALTER TABLE warehouses DROP CONSTRAINT IF EXISTS warehouses_category_id_fkey;
+ALTER TABLE warehouses DROP COLUMN IF EXISTS is_active;
+ALTER TABLE warehouses DROP COLUMN IF EXISTS description;
+ALTER TABLE warehouses DROP COLUMN IF EXISTS unit;
+ALTER TABLE warehouses DROP COLUMN IF EXISTS category_id;
+ALTER TABLE warehouses DROP COLUMN IF EXISTS barcode;
+ALTER TABLE warehouses DROP COLUMN IF EXISTS sku;
+
+-- Удаление категорий
+DROP TABLE IF EXISTS categories;
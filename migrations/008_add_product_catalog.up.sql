@@ -0,0 +1,35 @@
+-- Иерархия категорий товаров
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    parent_id integer,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT categories_parent_id_fkey FOREIGN KEY (parent_id)
+        REFERENCES categories (id) ON DELETE RESTRICT
+);
+
+-- Название категории уникально в пределах родителя
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_parent_name ON categories (COALESCE(parent_id, 0), name);
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
+
+-- Атрибуты товара в каталоге
+ALTER TABLE warehouses ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+ALTER TABLE warehouses ADD COLUMN IF NOT EXISTS barcode VARCHAR(64);
+ALTER TABLE warehouses ADD COLUMN IF NOT EXISTS category_id integer;
+ALTER TABLE warehouses ADD COLUMN IF NOT EXISTS unit VARCHAR(16) NOT NULL DEFAULT 'шт';
+ALTER TABLE warehouses ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE warehouses ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT true;
+
+ALTER TABLE warehouses DROP CONSTRAINT IF EXISTS warehouses_category_id_fkey;
+ALTER TABLE warehouses ADD CONSTRAINT warehouses_category_id_fkey FOREIGN KEY (category_id)
+    REFERENCES categories (id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warehouses_sku ON warehouses(sku);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warehouses_barcode ON warehouses(barcode);
+CREATE INDEX IF NOT EXISTS idx_warehouses_category_id ON warehouses(category_id);
+
+-- Акции могут действовать на категорию (включая подкатегории)
+ALTER TABLE promotions ADD COLUMN IF NOT EXISTS category_id integer;
+ALTER TABLE promotions DROP CONSTRAINT IF EXISTS promotions_category_id_fkey;
+ALTER TABLE promotions ADD CONSTRAINT promotions_category_id_fkey FOREIGN KEY (category_id)
+    REFERENCES categories (id) ON DELETE CASCADE;
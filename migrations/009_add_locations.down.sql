@@ -0,0 +1,15 @@
+-- Удаление документов перемещения
+DROP TABLE IF EXISTS stock_transfer_items;
+DROP TABLE IF EXISTS stock_transfers;
+
+-- Удаление локации продажи
+DROP INDEX IF EXISTS idx_sales_location_id;
+ALTER TABLE sales DROP CONSTRAINT IF EXISTS sales_location_id_fkey;
+ALTER TABLE sales DROP COLUMN IF EXISTS location_id;
+
+-- Удаление остатков по локациям (общий остаток остается в warehouses.quantity)
+DROP TABLE IF EXISTS stock_levels;
+DROP FUNCTION IF EXISTS sync_warehouse_quantity();
+
+-- Удаление локаций
+DROP TABLE IF EXISTS locations;
@@ -0,0 +1,105 @@
+-- Физические локации: торговые залы, подсобки, склады
+CREATE TABLE IF NOT EXISTS locations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    kind VARCHAR(20) NOT NULL DEFAULT 'store',
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT locations_kind_check CHECK (kind IN ('store', 'backroom', 'warehouse'))
+);
+
+-- Основная локация может быть только одна
+CREATE UNIQUE INDEX IF NOT EXISTS idx_locations_default ON locations(is_default) WHERE is_default;
+
+INSERT INTO locations (name, kind, is_default) VALUES
+    ('Основной склад', 'warehouse', true)
+ON CONFLICT DO NOTHING;
+
+-- Остатки товаров по локациям
+CREATE TABLE IF NOT EXISTS stock_levels (
+    warehouse_id integer NOT NULL,
+    location_id integer NOT NULL,
+    quantity integer NOT NULL DEFAULT 0,
+    CONSTRAINT stock_levels_pkey PRIMARY KEY (warehouse_id, location_id),
+    CONSTRAINT stock_levels_quantity_check CHECK (quantity >= 0),
+    CONSTRAINT stock_levels_warehouse_id_fkey FOREIGN KEY (warehouse_id)
+        REFERENCES warehouses (id) ON DELETE CASCADE,
+    CONSTRAINT stock_levels_location_id_fkey FOREIGN KEY (location_id)
+        REFERENCES locations (id) ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_levels_location_id ON stock_levels(location_id);
+
+-- Текущие остатки переносим на основную локацию
+INSERT INTO stock_levels (warehouse_id, location_id, quantity)
+SELECT w.id, l.id, GREATEST(COALESCE(w.quantity, 0), 0)
+FROM warehouses w CROSS JOIN locations l
+WHERE l.is_default
+ON CONFLICT DO NOTHING;
+
+-- Функция для поддержания общего остатка товара (warehouses.quantity) равным сумме по локациям
+CREATE OR REPLACE FUNCTION sync_warehouse_quantity()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE warehouses
+        SET quantity = (SELECT COALESCE(SUM(quantity), 0) FROM stock_levels WHERE warehouse_id = OLD.warehouse_id)
+        WHERE id = OLD.warehouse_id;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE warehouses
+        SET quantity = (SELECT COALESCE(SUM(quantity), 0) FROM stock_levels WHERE warehouse_id = NEW.warehouse_id)
+        WHERE id = NEW.warehouse_id;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Триггер для синхронизации общего остатка
+DROP TRIGGER IF EXISTS sync_warehouse_quantity ON stock_levels;
+CREATE TRIGGER sync_warehouse_quantity
+    AFTER INSERT OR UPDATE OR DELETE ON stock_levels
+    FOR EACH ROW
+    EXECUTE FUNCTION sync_warehouse_quantity();
+
+-- Локация продажи
+ALTER TABLE sales ADD COLUMN IF NOT EXISTS location_id integer;
+ALTER TABLE sales DROP CONSTRAINT IF EXISTS sales_location_id_fkey;
+ALTER TABLE sales ADD CONSTRAINT sales_location_id_fkey FOREIGN KEY (location_id)
+    REFERENCES locations (id) ON DELETE RESTRICT;
+
+UPDATE sales SET location_id = (SELECT id FROM locations WHERE is_default) WHERE location_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_sales_location_id ON sales(location_id);
+
+-- Документы перемещения товара между локациями
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id SERIAL PRIMARY KEY,
+    from_location_id integer NOT NULL,
+    to_location_id integer NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT stock_transfers_locations_check CHECK (from_location_id <> to_location_id),
+    CONSTRAINT stock_transfers_from_location_id_fkey FOREIGN KEY (from_location_id)
+        REFERENCES locations (id) ON DELETE RESTRICT,
+    CONSTRAINT stock_transfers_to_location_id_fkey FOREIGN KEY (to_location_id)
+        REFERENCES locations (id) ON DELETE RESTRICT
+);
+
+CREATE TABLE IF NOT EXISTS stock_transfer_items (
+    id SERIAL PRIMARY KEY,
+    transfer_id integer NOT NULL,
+    warehouse_id integer NOT NULL,
+    quantity integer NOT NULL,
+    CONSTRAINT stock_transfer_items_quantity_check CHECK (quantity > 0),
+    CONSTRAINT stock_transfer_items_transfer_id_fkey FOREIGN KEY (transfer_id)
+        REFERENCES stock_transfers (id) ON DELETE CASCADE,
+    CONSTRAINT stock_transfer_items_warehouse_id_fkey FOREIGN KEY (warehouse_id)
+        REFERENCES warehouses (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfer_items_transfer_id ON stock_transfer_items(transfer_id);
@@ -0,0 +1,7 @@
+-- Удаление журнала событий
+DROP TABLE IF EXISTS events;
+
+-- Удаление точек заказа
+ALTER TABLE warehouses DROP CONSTRAINT IF EXISTS warehouses_reorder_check;
+ALTER TABLE warehouses DROP COLUMN IF EXISTS reorder_quantity;
+ALTER TABLE warehouses DROP COLUMN IF EXISTS reorder_point;
@@ -0,0 +1,20 @@
+-- Точка заказа: при остатке на этом уровне или ниже товар нужно дозаказать (0 - не отслеживать)
+ALTER TABLE warehouses ADD COLUMN IF NOT EXISTS reorder_point integer NOT NULL DEFAULT 0;
+-- Рекомендуемый объем заказа
+ALTER TABLE warehouses ADD COLUMN IF NOT EXISTS reorder_quantity integer NOT NULL DEFAULT 0;
+
+ALTER TABLE warehouses DROP CONSTRAINT IF EXISTS warehouses_reorder_check;
+ALTER TABLE warehouses ADD CONSTRAINT warehouses_reorder_check
+    CHECK (reorder_point >= 0 AND reorder_quantity >= 0);
+
+-- Журнал событий и оповещений (низкий остаток и т.п.)
+CREATE TABLE IF NOT EXISTS events (
+    id SERIAL PRIMARY KEY,
+    type VARCHAR(50) NOT NULL,
+    message TEXT NOT NULL,
+    payload JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_type ON events(type);
+CREATE INDEX IF NOT EXISTS idx_events_created_at ON events(created_at);
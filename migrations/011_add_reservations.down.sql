@@ -0,0 +1,2 @@
+-- Удаление резервов
+DROP TABLE IF EXISTS reservations;
@@ -0,0 +1,29 @@
+-- Резервы товара под заказы до оплаты.
+-- Резерв уменьшает доступный, но не фактический остаток на локации.
+CREATE TABLE IF NOT EXISTS reservations (
+    id SERIAL PRIMARY KEY,
+    warehouse_id integer NOT NULL,
+    location_id integer NOT NULL,
+    customer_id integer,
+    quantity integer NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    note TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    sale_id integer,
+    created_by VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT reservations_quantity_check CHECK (quantity > 0),
+    CONSTRAINT reservations_status_check CHECK (status IN ('active', 'converted', 'released', 'expired')),
+    CONSTRAINT reservations_warehouse_id_fkey FOREIGN KEY (warehouse_id)
+        REFERENCES warehouses (id) ON DELETE CASCADE,
+    CONSTRAINT reservations_location_id_fkey FOREIGN KEY (location_id)
+        REFERENCES locations (id) ON DELETE RESTRICT,
+    CONSTRAINT reservations_customer_id_fkey FOREIGN KEY (customer_id)
+        REFERENCES customers (id) ON DELETE SET NULL,
+    CONSTRAINT reservations_sale_id_fkey FOREIGN KEY (sale_id)
+        REFERENCES sales (id) ON DELETE SET NULL
+);
+
+-- Для расчета доступного остатка нужны только действующие резервы
+CREATE INDEX IF NOT EXISTS idx_reservations_active ON reservations(warehouse_id, location_id) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_reservations_expires_at ON reservations(expires_at) WHERE status = 'active';
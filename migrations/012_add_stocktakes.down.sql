@@ -0,0 +1,4 @@
+-- Удаление корректировок и инвентаризаций
+DROP TABLE IF EXISTS stock_adjustments;
+DROP TABLE IF EXISTS stocktake_items;
+DROP TABLE IF EXISTS stocktakes;
@@ -0,0 +1,53 @@
+-- Инвентаризация: сессия пересчета товара на локации
+CREATE TABLE IF NOT EXISTS stocktakes (
+    id SERIAL PRIMARY KEY,
+    location_id integer NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    note TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    posted_by VARCHAR(50),
+    posted_at TIMESTAMP,
+    CONSTRAINT stocktakes_status_check CHECK (status IN ('open', 'posted', 'cancelled')),
+    CONSTRAINT stocktakes_location_id_fkey FOREIGN KEY (location_id)
+        REFERENCES locations (id) ON DELETE RESTRICT
+);
+
+-- Посчитанное количество товара. Учетный остаток фиксируется при проведении.
+CREATE TABLE IF NOT EXISTS stocktake_items (
+    stocktake_id integer NOT NULL,
+    warehouse_id integer NOT NULL,
+    counted_quantity integer NOT NULL,
+    system_quantity integer,
+    unit_value DECIMAL(10,2),
+    counted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT stocktake_items_pkey PRIMARY KEY (stocktake_id, warehouse_id),
+    CONSTRAINT stocktake_items_counted_quantity_check CHECK (counted_quantity >= 0),
+    CONSTRAINT stocktake_items_stocktake_id_fkey FOREIGN KEY (stocktake_id)
+        REFERENCES stocktakes (id) ON DELETE CASCADE,
+    CONSTRAINT stocktake_items_warehouse_id_fkey FOREIGN KEY (warehouse_id)
+        REFERENCES warehouses (id) ON DELETE CASCADE
+);
+
+-- Корректировки остатков (движения товара вне продаж и перемещений)
+CREATE TABLE IF NOT EXISTS stock_adjustments (
+    id SERIAL PRIMARY KEY,
+    warehouse_id integer NOT NULL,
+    location_id integer NOT NULL,
+    quantity integer NOT NULL,
+    unit_value DECIMAL(10,2) NOT NULL DEFAULT 0,
+    reason VARCHAR(20) NOT NULL,
+    stocktake_id integer,
+    created_by VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT stock_adjustments_quantity_check CHECK (quantity <> 0),
+    CONSTRAINT stock_adjustments_warehouse_id_fkey FOREIGN KEY (warehouse_id)
+        REFERENCES warehouses (id) ON DELETE CASCADE,
+    CONSTRAINT stock_adjustments_location_id_fkey FOREIGN KEY (location_id)
+        REFERENCES locations (id) ON DELETE RESTRICT,
+    CONSTRAINT stock_adjustments_stocktake_id_fkey FOREIGN KEY (stocktake_id)
+        REFERENCES stocktakes (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_adjustments_created_at ON stock_adjustments(created_at);
+CREATE INDEX IF NOT EXISTS idx_stock_adjustments_stocktake_id ON stock_adjustments(stocktake_id);
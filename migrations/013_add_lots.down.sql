@@ -0,0 +1,3 @@
+-- Удаление партий
+DROP TABLE IF EXISTS sale_lots;
+DROP TABLE IF EXISTS lots;
@@ -0,0 +1,33 @@
+-- Партии товара с номером и сроком годности. Учет партий необязателен:
+-- товар без партий продается как раньше, остаток вне партий считается неучтенным.
+CREATE TABLE IF NOT EXISTS lots (
+    id SERIAL PRIMARY KEY,
+    warehouse_id integer NOT NULL,
+    location_id integer NOT NULL,
+    lot_number VARCHAR(50) NOT NULL,
+    expiry_date DATE,
+    quantity integer NOT NULL,
+    received_quantity integer NOT NULL,
+    received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT lots_quantity_check CHECK (quantity >= 0),
+    CONSTRAINT lots_warehouse_location_number_key UNIQUE (warehouse_id, location_id, lot_number),
+    CONSTRAINT lots_warehouse_id_fkey FOREIGN KEY (warehouse_id)
+        REFERENCES warehouses (id) ON DELETE CASCADE,
+    CONSTRAINT lots_location_id_fkey FOREIGN KEY (location_id)
+        REFERENCES locations (id) ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_lots_expiry_date ON lots(expiry_date) WHERE quantity > 0;
+
+-- Из каких партий списан товар продажи (для прослеживаемости и возврата при удалении продажи)
+CREATE TABLE IF NOT EXISTS sale_lots (
+    sale_id integer NOT NULL,
+    lot_id integer NOT NULL,
+    quantity integer NOT NULL,
+    CONSTRAINT sale_lots_pkey PRIMARY KEY (sale_id, lot_id),
+    CONSTRAINT sale_lots_quantity_check CHECK (quantity > 0),
+    CONSTRAINT sale_lots_sale_id_fkey FOREIGN KEY (sale_id)
+        REFERENCES sales (id) ON DELETE CASCADE,
+    CONSTRAINT sale_lots_lot_id_fkey FOREIGN KEY (lot_id)
+        REFERENCES lots (id) ON DELETE CASCADE
+);
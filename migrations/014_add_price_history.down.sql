@@ -0,0 +1,2 @@
+-- Удаление истории цен
+DROP TABLE IF EXISTS price_history;
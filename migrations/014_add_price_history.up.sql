@@ -0,0 +1,22 @@
+-- История цен товара. Записи с effective_at в будущем - запланированные изменения,
+-- они применяются к warehouses.amount фоновой задачей.
+CREATE TABLE IF NOT EXISTS price_history (
+    id SERIAL PRIMARY KEY,
+    warehouse_id integer NOT NULL,
+    amount numeric NOT NULL,
+    effective_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT price_history_amount_check CHECK (amount >= 0),
+    CONSTRAINT price_history_warehouse_id_fkey FOREIGN KEY (warehouse_id)
+        REFERENCES warehouses (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_history_warehouse_effective ON price_history(warehouse_id, effective_at);
+
+-- Текущие цены существующих товаров становятся началом истории
+INSERT INTO price_history (warehouse_id, amount)
+SELECT w.id, w.amount
+FROM warehouses w
+WHERE w.amount IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM price_history p WHERE p.warehouse_id = w.id);
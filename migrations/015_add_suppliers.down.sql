@@ -0,0 +1,4 @@
+-- Удаление поставщиков
+ALTER TABLE IF EXISTS charges DROP COLUMN IF EXISTS supplier_id;
+DROP TABLE IF EXISTS product_suppliers;
+DROP TABLE IF EXISTS suppliers;
@@ -0,0 +1,39 @@
+-- Поставщики
+CREATE TABLE IF NOT EXISTS suppliers (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    contact_name VARCHAR(255) NOT NULL DEFAULT '',
+    phone VARCHAR(50) NOT NULL DEFAULT '',
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    payment_terms_days integer NOT NULL DEFAULT 0,
+    notes TEXT NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT suppliers_name_key UNIQUE (name),
+    CONSTRAINT suppliers_payment_terms_days_check CHECK (payment_terms_days >= 0)
+);
+
+-- Товары поставщиков: артикул поставщика и последняя закупочная цена.
+-- У товара может быть один основной поставщик, по нему группируется список закупки.
+CREATE TABLE IF NOT EXISTS product_suppliers (
+    warehouse_id integer NOT NULL,
+    supplier_id integer NOT NULL,
+    supplier_sku VARCHAR(64) NOT NULL DEFAULT '',
+    last_purchase_price numeric,
+    last_purchased_at TIMESTAMP,
+    is_primary BOOLEAN NOT NULL DEFAULT false,
+    CONSTRAINT product_suppliers_pkey PRIMARY KEY (warehouse_id, supplier_id),
+    CONSTRAINT product_suppliers_warehouse_id_fkey FOREIGN KEY (warehouse_id)
+        REFERENCES warehouses (id) ON DELETE CASCADE,
+    CONSTRAINT product_suppliers_supplier_id_fkey FOREIGN KEY (supplier_id)
+        REFERENCES suppliers (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_suppliers_primary ON product_suppliers(warehouse_id) WHERE is_primary;
+CREATE INDEX IF NOT EXISTS idx_product_suppliers_supplier_id ON product_suppliers(supplier_id);
+
+-- Расход может относиться к поставщику. Поставщика с расходами удалить нельзя.
+ALTER TABLE charges ADD COLUMN IF NOT EXISTS supplier_id integer
+    CONSTRAINT charges_supplier_id_fkey REFERENCES suppliers (id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_charges_supplier_id ON charges(supplier_id);
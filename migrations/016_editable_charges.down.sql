@@ -0,0 +1,13 @@
+-- Удаление защиты закрытого периода при изменении расходов
+DROP TRIGGER IF EXISTS prevent_old_charges_changes ON charges;
+DROP FUNCTION IF EXISTS prevent_old_expenses_changes();
+
+-- Проверка суммы снова только при создании
+DROP TRIGGER IF EXISTS prevent_high_charge_amount ON charges;
+CREATE TRIGGER prevent_high_charge_amount
+    BEFORE INSERT ON charges
+    FOR EACH ROW
+    EXECUTE FUNCTION check_charge_amount();
+
+ALTER TABLE IF EXISTS charges DROP COLUMN IF EXISTS document_number;
+ALTER TABLE IF EXISTS charges DROP COLUMN IF EXISTS description;
@@ -0,0 +1,34 @@
+-- Описание и номер документа (счета, акта) расхода
+ALTER TABLE charges ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE charges ADD COLUMN IF NOT EXISTS document_number VARCHAR(64) NOT NULL DEFAULT '';
+
+-- Проверка максимальной суммы действует и при изменении расхода
+DROP TRIGGER IF EXISTS prevent_high_charge_amount ON charges;
+CREATE TRIGGER prevent_high_charge_amount
+    BEFORE INSERT OR UPDATE ON charges
+    FOR EACH ROW
+    EXECUTE FUNCTION check_charge_amount();
+
+-- Функция для защиты закрытого периода: расходы старше одного месяца нельзя
+-- изменять, и нельзя вносить или переносить расход в этот период
+CREATE OR REPLACE FUNCTION prevent_old_expenses_changes()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND OLD.charge_date < (CURRENT_DATE - INTERVAL '1 month') THEN
+        RAISE EXCEPTION 'Запрещено изменять расходы старше одного месяца';
+    END IF;
+
+    IF NEW.charge_date < (CURRENT_DATE - INTERVAL '1 month') THEN
+        RAISE EXCEPTION 'Запрещено вносить расходы с датой старше одного месяца. Дата расхода: %.', NEW.charge_date::DATE;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Триггер для защиты закрытого периода при создании и изменении расходов
+DROP TRIGGER IF EXISTS prevent_old_charges_changes ON charges;
+CREATE TRIGGER prevent_old_charges_changes
+    BEFORE INSERT OR UPDATE ON charges
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_old_expenses_changes();
@@ -0,0 +1,3 @@
+-- Удаление повторяющихся расходов (созданные расходы остаются)
+DROP TABLE IF EXISTS recurring_charge_runs;
+DROP TABLE IF EXISTS recurring_charges;
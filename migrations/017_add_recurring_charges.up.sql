@@ -0,0 +1,40 @@
+-- Шаблоны повторяющихся расходов (аренда, зарплата, хостинг)
+CREATE TABLE IF NOT EXISTS recurring_charges (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    expense_item_id integer NOT NULL,
+    supplier_id integer,
+    amount numeric NOT NULL,
+    frequency VARCHAR(10) NOT NULL,
+    day_of_month integer,
+    day_of_week integer,
+    starts_on DATE NOT NULL DEFAULT CURRENT_DATE,
+    ends_on DATE,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT recurring_charges_amount_check CHECK (amount >= 0),
+    CONSTRAINT recurring_charges_schedule_check CHECK (
+        (frequency = 'monthly' AND day_of_month BETWEEN 1 AND 31) OR
+        (frequency = 'weekly' AND day_of_week BETWEEN 0 AND 6)
+    ),
+    CONSTRAINT recurring_charges_period_check CHECK (ends_on IS NULL OR ends_on >= starts_on),
+    CONSTRAINT recurring_charges_expense_item_id_fkey FOREIGN KEY (expense_item_id)
+        REFERENCES expense_items (id) ON DELETE CASCADE,
+    CONSTRAINT recurring_charges_supplier_id_fkey FOREIGN KEY (supplier_id)
+        REFERENCES suppliers (id) ON DELETE SET NULL
+);
+
+-- Созданные по шаблону расходы. Уникальность (шаблон, дата) делает создание идемпотентным:
+-- при перезапуске и в нескольких репликах расход за дату создается один раз.
+CREATE TABLE IF NOT EXISTS recurring_charge_runs (
+    template_id integer NOT NULL,
+    occurrence_date DATE NOT NULL,
+    charge_id integer,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT recurring_charge_runs_pkey PRIMARY KEY (template_id, occurrence_date),
+    CONSTRAINT recurring_charge_runs_template_id_fkey FOREIGN KEY (template_id)
+        REFERENCES recurring_charges (id) ON DELETE CASCADE,
+    CONSTRAINT recurring_charge_runs_charge_id_fkey FOREIGN KEY (charge_id)
+        REFERENCES charges (id) ON DELETE SET NULL
+);
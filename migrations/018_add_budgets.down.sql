@@ -0,0 +1,2 @@
+-- Удаление бюджетов статей расходов
+DROP TABLE IF EXISTS budgets;
@@ -0,0 +1,17 @@
+-- Бюджеты статей расходов на месяц. При hard_limit расход сверх бюджета не создается,
+-- без него превышение только отмечается в ответе и журнале событий.
+CREATE TABLE IF NOT EXISTS budgets (
+    id SERIAL PRIMARY KEY,
+    expense_item_id integer NOT NULL,
+    year integer NOT NULL,
+    month integer NOT NULL,
+    amount numeric NOT NULL,
+    hard_limit BOOLEAN NOT NULL DEFAULT false,
+    created_by VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT budgets_amount_check CHECK (amount >= 0),
+    CONSTRAINT budgets_period_check CHECK (month BETWEEN 1 AND 12 AND year BETWEEN 2000 AND 2100),
+    CONSTRAINT budgets_expense_item_period_key UNIQUE (expense_item_id, year, month),
+    CONSTRAINT budgets_expense_item_id_fkey FOREIGN KEY (expense_item_id)
+        REFERENCES expense_items (id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+-- Удаление иерархии статей расходов
+DROP INDEX IF EXISTS idx_expense_items_parent_id;
+DROP INDEX IF EXISTS idx_expense_items_parent_name;
+ALTER TABLE IF EXISTS expense_items DROP CONSTRAINT IF EXISTS expense_items_parent_id_fkey;
+ALTER TABLE IF EXISTS expense_items DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,10 @@
+-- Иерархия статей расходов (Операционные -> Коммунальные услуги -> Электричество)
+ALTER TABLE expense_items ADD COLUMN IF NOT EXISTS parent_id integer;
+
+ALTER TABLE expense_items DROP CONSTRAINT IF EXISTS expense_items_parent_id_fkey;
+ALTER TABLE expense_items ADD CONSTRAINT expense_items_parent_id_fkey FOREIGN KEY (parent_id)
+    REFERENCES expense_items (id) ON DELETE RESTRICT;
+
+-- Название статьи уникально в пределах родителя
+CREATE UNIQUE INDEX IF NOT EXISTS idx_expense_items_parent_name ON expense_items (COALESCE(parent_id, 0), name);
+CREATE INDEX IF NOT EXISTS idx_expense_items_parent_id ON expense_items(parent_id);
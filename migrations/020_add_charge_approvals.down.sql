@@ -0,0 +1,23 @@
+-- Возврат проверки максимальной суммы к общей ошибке
+CREATE OR REPLACE FUNCTION check_charge_amount()
+RETURNS TRIGGER AS $$
+DECLARE
+    max_amount CONSTANT NUMERIC := 1000000;
+BEGIN
+    IF NEW.amount > max_amount THEN
+        RAISE EXCEPTION 'Сумма расхода (%) превышает максимально допустимую сумму (%)', NEW.amount, max_amount;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Удаление утверждения расходов
+DROP INDEX IF EXISTS idx_charges_pending;
+ALTER TABLE IF EXISTS charges DROP CONSTRAINT IF EXISTS charges_status_check;
+ALTER TABLE IF EXISTS charges DROP COLUMN IF EXISTS review_comment;
+ALTER TABLE IF EXISTS charges DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE IF EXISTS charges DROP COLUMN IF EXISTS reviewed_by;
+ALTER TABLE IF EXISTS charges DROP COLUMN IF EXISTS status;
+
+ALTER TABLE IF EXISTS expense_items DROP CONSTRAINT IF EXISTS expense_items_approval_threshold_check;
+ALTER TABLE IF EXISTS expense_items DROP COLUMN IF EXISTS approval_threshold;
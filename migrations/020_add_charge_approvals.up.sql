@@ -0,0 +1,31 @@
+-- Порог суммы, выше которого расход по статье требует утверждения.
+-- NULL - действует порог ближайшей родительской статьи, если он задан.
+ALTER TABLE expense_items ADD COLUMN IF NOT EXISTS approval_threshold numeric;
+ALTER TABLE expense_items DROP CONSTRAINT IF EXISTS expense_items_approval_threshold_check;
+ALTER TABLE expense_items ADD CONSTRAINT expense_items_approval_threshold_check CHECK (approval_threshold >= 0);
+
+-- Статус утверждения расхода, существующие расходы считаются утвержденными
+ALTER TABLE charges ADD COLUMN IF NOT EXISTS status VARCHAR(10) NOT NULL DEFAULT 'approved';
+ALTER TABLE charges ADD COLUMN IF NOT EXISTS reviewed_by VARCHAR(50);
+ALTER TABLE charges ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP;
+ALTER TABLE charges ADD COLUMN IF NOT EXISTS review_comment TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE charges DROP CONSTRAINT IF EXISTS charges_status_check;
+ALTER TABLE charges ADD CONSTRAINT charges_status_check CHECK (status IN ('pending', 'approved', 'rejected'));
+
+CREATE INDEX IF NOT EXISTS idx_charges_pending ON charges(charge_date) WHERE status = 'pending';
+
+-- Превышение максимальной суммы выбрасывается как нарушение проверки (check_violation),
+-- чтобы API отличало его от прочих ошибок триггеров
+CREATE OR REPLACE FUNCTION check_charge_amount()
+RETURNS TRIGGER AS $$
+DECLARE
+    max_amount CONSTANT NUMERIC := 1000000;
+BEGIN
+    IF NEW.amount > max_amount THEN
+        RAISE EXCEPTION 'Сумма расхода (%) превышает максимально допустимую сумму (%)', NEW.amount, max_amount
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
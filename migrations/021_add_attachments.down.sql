@@ -0,0 +1,2 @@
+-- Удаление вложений (файлы в хранилище не удаляются)
+DROP TABLE IF EXISTS attachments;
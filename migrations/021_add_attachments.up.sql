@@ -0,0 +1,28 @@
+-- Вложения (сканы счетов, актов, чеков) к расходам, продажам и приемкам партий товара.
+-- Содержимое файла лежит в хранилище под ключом storage_key, в БД - только метаданные.
+-- При удалении владельца записи о вложениях удаляются, файлы остаются в хранилище.
+CREATE TABLE IF NOT EXISTS attachments (
+    id SERIAL PRIMARY KEY,
+    charge_id integer,
+    sale_id integer,
+    lot_id integer,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size bigint NOT NULL,
+    storage_key VARCHAR(100) NOT NULL,
+    uploaded_by VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT attachments_owner_check CHECK (num_nonnulls(charge_id, sale_id, lot_id) = 1),
+    CONSTRAINT attachments_size_check CHECK (size >= 0),
+    CONSTRAINT attachments_storage_key_key UNIQUE (storage_key),
+    CONSTRAINT attachments_charge_id_fkey FOREIGN KEY (charge_id)
+        REFERENCES charges (id) ON DELETE CASCADE,
+    CONSTRAINT attachments_sale_id_fkey FOREIGN KEY (sale_id)
+        REFERENCES sales (id) ON DELETE CASCADE,
+    CONSTRAINT attachments_lot_id_fkey FOREIGN KEY (lot_id)
+        REFERENCES lots (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_charge_id ON attachments(charge_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_sale_id ON attachments(sale_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_lot_id ON attachments(lot_id);
@@ -0,0 +1,42 @@
+-- Удаление учетных периодов
+DROP TRIGGER IF EXISTS prevent_closed_period_charges ON charges;
+DROP FUNCTION IF EXISTS prevent_closed_period_charges();
+DROP TABLE IF EXISTS accounting_periods;
+
+-- Возврат запрета на изменение расходов старше одного месяца
+CREATE OR REPLACE FUNCTION prevent_old_expenses_changes()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND OLD.charge_date < (CURRENT_DATE - INTERVAL '1 month') THEN
+        RAISE EXCEPTION 'Запрещено изменять расходы старше одного месяца';
+    END IF;
+
+    IF NEW.charge_date < (CURRENT_DATE - INTERVAL '1 month') THEN
+        RAISE EXCEPTION 'Запрещено вносить расходы с датой старше одного месяца. Дата расхода: %.', NEW.charge_date::DATE;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS prevent_old_charges_changes ON charges;
+CREATE TRIGGER prevent_old_charges_changes
+    BEFORE INSERT OR UPDATE ON charges
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_old_expenses_changes();
+
+CREATE OR REPLACE FUNCTION prevent_old_expenses_deletion()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD.charge_date < (CURRENT_DATE - INTERVAL '1 month') THEN
+        RAISE EXCEPTION 'Запрещено удалять расходы старше одного месяца';
+    END IF;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS prevent_old_charges_deletion ON charges;
+CREATE TRIGGER prevent_old_charges_deletion
+    BEFORE DELETE ON charges
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_old_expenses_deletion();
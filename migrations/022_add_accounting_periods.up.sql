@@ -0,0 +1,57 @@
+-- Учетные периоды (месяцы). Месяц без записи считается открытым. В закрытом периоде
+-- продажи, возвраты, расходы и движения товара запрещены, при закрытии сохраняются
+-- итоги периода (snapshot), при повторном закрытии они пересчитываются.
+CREATE TABLE IF NOT EXISTS accounting_periods (
+    id SERIAL PRIMARY KEY,
+    year integer NOT NULL,
+    month integer NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'open',
+    snapshot JSONB,
+    closed_by VARCHAR(50),
+    closed_at TIMESTAMP,
+    reopened_by VARCHAR(50),
+    reopened_at TIMESTAMP,
+    CONSTRAINT accounting_periods_status_check CHECK (status IN ('open', 'closed')),
+    CONSTRAINT accounting_periods_period_check CHECK (month BETWEEN 1 AND 12 AND year BETWEEN 2000 AND 2100),
+    CONSTRAINT accounting_periods_period_key UNIQUE (year, month)
+);
+
+-- Расходы защищает только закрытие учетного периода. Прежние скользящие запреты на изменение
+-- и удаление расходов старше месяца не давали утвердить такой расход, а значит и закрыть его период.
+DROP TRIGGER IF EXISTS prevent_old_charges_changes ON charges;
+DROP FUNCTION IF EXISTS prevent_old_expenses_changes();
+DROP TRIGGER IF EXISTS prevent_old_charges_deletion ON charges;
+DROP FUNCTION IF EXISTS prevent_old_expenses_deletion();
+
+CREATE OR REPLACE FUNCTION prevent_closed_period_charges()
+RETURNS TRIGGER AS $$
+DECLARE
+    checked_date TIMESTAMP;
+BEGIN
+    FOREACH checked_date IN ARRAY CASE TG_OP
+        WHEN 'INSERT' THEN ARRAY[NEW.charge_date]
+        WHEN 'DELETE' THEN ARRAY[OLD.charge_date]
+        ELSE ARRAY[OLD.charge_date, NEW.charge_date]
+    END LOOP
+        IF EXISTS (
+            SELECT 1 FROM accounting_periods
+            WHERE status = 'closed'
+              AND year = EXTRACT(YEAR FROM checked_date)
+              AND month = EXTRACT(MONTH FROM checked_date)
+        ) THEN
+            RAISE EXCEPTION 'Учетный период % закрыт, изменения в нем запрещены', to_char(checked_date, 'MM.YYYY');
+        END IF;
+    END LOOP;
+
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS prevent_closed_period_charges ON charges;
+CREATE TRIGGER prevent_closed_period_charges
+    BEFORE INSERT OR UPDATE OR DELETE ON charges
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_closed_period_charges();
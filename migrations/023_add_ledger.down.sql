@@ -0,0 +1,6 @@
+-- Удаление проводок и плана счетов
+DROP TABLE IF EXISTS journal_entries;
+ALTER TABLE IF EXISTS expense_items DROP CONSTRAINT IF EXISTS expense_items_account_id_fkey;
+ALTER TABLE IF EXISTS expense_items DROP COLUMN IF EXISTS account_id;
+DROP TABLE IF EXISTS payment_method_accounts;
+DROP TABLE IF EXISTS accounts;
@@ -0,0 +1,102 @@
+-- План счетов. Коды совпадают с планом счетов 1С, type - активный, пассивный
+-- или активно-пассивный счет.
+CREATE TABLE IF NOT EXISTS accounts (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(16) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(16) NOT NULL DEFAULT 'active_passive',
+    CONSTRAINT accounts_code_key UNIQUE (code),
+    CONSTRAINT accounts_type_check CHECK (type IN ('active', 'passive', 'active_passive'))
+);
+
+INSERT INTO accounts (code, name, type) VALUES
+    ('41', 'Товары', 'active'),
+    ('44', 'Расходы на продажу', 'active'),
+    ('50', 'Касса', 'active'),
+    ('51', 'Расчетные счета', 'active'),
+    ('57', 'Переводы в пути', 'active'),
+    ('60', 'Расчеты с поставщиками и подрядчиками', 'active_passive'),
+    ('62', 'Расчеты с покупателями и заказчиками', 'active_passive'),
+    ('68', 'Расчеты по налогам и сборам', 'active_passive'),
+    ('76', 'Расчеты с разными дебиторами и кредиторами', 'active_passive'),
+    ('90.01', 'Выручка', 'passive'),
+    ('90.03', 'Налог на добавленную стоимость', 'active')
+ON CONFLICT (code) DO NOTHING;
+
+-- Счет учета денег по способу оплаты продажи
+CREATE TABLE IF NOT EXISTS payment_method_accounts (
+    method VARCHAR(20) PRIMARY KEY,
+    account_id integer NOT NULL,
+    CONSTRAINT payment_method_accounts_method_check CHECK (method IN ('cash', 'card', 'transfer', 'gift_card')),
+    CONSTRAINT payment_method_accounts_account_id_fkey FOREIGN KEY (account_id)
+        REFERENCES accounts (id) ON DELETE RESTRICT
+);
+
+INSERT INTO payment_method_accounts (method, account_id)
+SELECT m.method, a.id
+FROM (VALUES ('cash', '50'), ('card', '57'), ('transfer', '51'), ('gift_card', '62')) AS m(method, code)
+JOIN accounts a ON a.code = m.code
+ON CONFLICT (method) DO NOTHING;
+
+-- Счет затрат статьи расходов. Без счета действует счет родительской статьи, затем 44.
+ALTER TABLE expense_items ADD COLUMN IF NOT EXISTS account_id integer;
+ALTER TABLE expense_items DROP CONSTRAINT IF EXISTS expense_items_account_id_fkey;
+ALTER TABLE expense_items ADD CONSTRAINT expense_items_account_id_fkey FOREIGN KEY (account_id)
+    REFERENCES accounts (id) ON DELETE RESTRICT;
+
+-- Проводки. Каждая запись - пара дебет/кредит на одну сумму, поэтому журнал сбалансирован.
+-- source_type и source_id - документ, которым сделана проводка (продажа, возврат, приемка, расход).
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id SERIAL PRIMARY KEY,
+    entry_date TIMESTAMP NOT NULL,
+    debit_account_id integer NOT NULL,
+    credit_account_id integer NOT NULL,
+    amount DECIMAL(12,2) NOT NULL,
+    source_type VARCHAR(16) NOT NULL,
+    source_id integer NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT journal_entries_amount_check CHECK (amount > 0),
+    CONSTRAINT journal_entries_accounts_check CHECK (debit_account_id <> credit_account_id),
+    CONSTRAINT journal_entries_source_type_check CHECK (source_type IN ('sale', 'refund', 'purchase', 'charge')),
+    CONSTRAINT journal_entries_debit_account_id_fkey FOREIGN KEY (debit_account_id)
+        REFERENCES accounts (id) ON DELETE RESTRICT,
+    CONSTRAINT journal_entries_credit_account_id_fkey FOREIGN KEY (credit_account_id)
+        REFERENCES accounts (id) ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_entries_entry_date ON journal_entries (entry_date);
+CREATE INDEX IF NOT EXISTS idx_journal_entries_source ON journal_entries (source_type, source_id);
+
+-- Проводки по уже существующим продажам и утвержденным расходам (только при первом запуске).
+-- У продаж, оформленных до появления оплат, записей в sale_payments нет: как и продажа
+-- без указанных оплат, они считаются оплаченными наличными на всю сумму.
+INSERT INTO journal_entries (entry_date, debit_account_id, credit_account_id, amount, source_type, source_id, description)
+SELECT * FROM (
+    SELECT s.sale_date, m.account_id, (SELECT id FROM accounts WHERE code = '90.01'),
+           SUM(COALESCE(p.amount, s.amount)), 'sale', s.id, 'Продажа №' || s.id
+    FROM sales s
+    LEFT JOIN sale_payments p ON p.sale_id = s.id
+    JOIN payment_method_accounts m ON m.method = COALESCE(p.method, 'cash')
+    GROUP BY s.id, s.sale_date, m.account_id
+    HAVING SUM(COALESCE(p.amount, s.amount)) > 0
+
+    UNION ALL
+
+    SELECT s.sale_date, (SELECT id FROM accounts WHERE code = '90.03'), (SELECT id FROM accounts WHERE code = '68'),
+           s.tax_amount, 'sale', s.id, 'НДС с продажи №' || s.id
+    FROM sales s
+    WHERE s.tax_amount > 0
+
+    UNION ALL
+
+    SELECT c.charge_date, (SELECT id FROM accounts WHERE code = '44'),
+           (SELECT id FROM accounts WHERE code = CASE WHEN c.supplier_id IS NULL THEN '76' ELSE '60' END),
+           c.amount, 'charge', c.id,
+           'Расход №' || c.id || ': ' || e.name
+               || CASE WHEN c.document_number <> '' THEN ', документ ' || c.document_number ELSE '' END
+    FROM charges c
+    JOIN expense_items e ON e.id = c.expense_item_id
+    WHERE c.status = 'approved' AND c.amount > 0
+) backfill
+WHERE NOT EXISTS (SELECT 1 FROM journal_entries);
@@ -0,0 +1,2 @@
+-- Удаление срока поставки поставщика
+ALTER TABLE IF EXISTS suppliers DROP COLUMN IF EXISTS lead_time_days;
@@ -0,0 +1,4 @@
+-- Срок поставки поставщика в днях: от заказа до поступления товара.
+-- NULL - срок неизвестен, прогноз закупок берет срок по умолчанию.
+ALTER TABLE suppliers ADD COLUMN IF NOT EXISTS lead_time_days integer
+    CONSTRAINT suppliers_lead_time_days_check CHECK (lead_time_days >= 0);
@@ -119,6 +119,10 @@ class APIClient:
             requests.get, url, params=params, headers=self._get_headers()
         )
 
+    def get_dashboard(self):
+        url = f"{self.base_url}/reports/dashboard"
+        return self._handle_request(requests.get, url, headers=self._get_headers())
+
     def get_top_products(self, start_date, end_date):
         url = f"{self.base_url}/reports/top-products"
         params = {"start_date": start_date, "end_date": end_date}
@@ -201,7 +205,9 @@ def logout():
 @app.route("/dashboard")
 @login_required
 def dashboard():
-    return render_template("dashboard.html", user=session.get("user"))
+    response, status_code = api_client.get_dashboard()
+    summary = response.get("data") if status_code == 200 else None
+    return render_template("dashboard.html", summary=summary, user=session.get("user"))
 
 
 @app.route("/health")